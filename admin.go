@@ -0,0 +1,456 @@
+package slogx
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxAdminBodySize limits the size of PATCH requests accepted by the admin handler.
+const maxAdminBodySize = 1 << 20
+
+// AdminAuthFunc authenticates a request to the admin handler. It returns the
+// principal that is written to the audit log, or false to reject the request.
+type AdminAuthFunc func(r *http.Request) (principal string, ok bool)
+
+// adminOptions holds AdminHandler settings.
+type adminOptions struct {
+	auth  AdminAuthFunc
+	audit *slog.Logger
+}
+
+// AdminOption is a functional configuration parameter for Logger.AdminHandler.
+type AdminOption func(*adminOptions)
+
+// WithAdminAuth authenticates every request with fn.
+func WithAdminAuth(fn AdminAuthFunc) AdminOption {
+	return func(o *adminOptions) {
+		o.auth = fn
+	}
+}
+
+// WithAdminToken authenticates requests carrying "Authorization: Bearer <token>".
+func WithAdminToken(token string) AdminOption {
+	return WithAdminAuth(
+		func(r *http.Request) (string, bool) {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				return "", false
+			}
+			return "token", true
+		},
+	)
+}
+
+// WithAdminAuditLogger writes the audit log of configuration changes to l instead
+// of the managed logger itself. Unlike the default, l applies its own level.
+func WithAdminAuditLogger(l *slog.Logger) AdminOption {
+	return func(o *adminOptions) {
+		o.audit = l
+	}
+}
+
+// adminConfig is the JSON view of the runtime-tunable part of Config.
+type adminConfig struct {
+	Version      uint64              `json:"version"`
+	Level        string              `json:"level"`
+	Format       Format              `json:"format"`
+	MaskKeys     map[string]MaskType `json:"mask_keys"`
+	RemoveKeys   []string            `json:"remove_keys"`
+	LevelNames   map[string]string   `json:"level_names"`
+	ContextKeys  []string            `json:"context_keys"`
+	LoggerLevels map[string]string   `json:"logger_levels"`
+}
+
+func newAdminConfig(cfg *Config) adminConfig {
+	v := adminConfig{
+		Version:      cfg.Version,
+		Level:        getLevelName(cfg.Level, cfg.LevelNames),
+		Format:       cfg.Format,
+		MaskKeys:     make(map[string]MaskType, len(cfg.MaskKeys)),
+		RemoveKeys:   make([]string, 0, len(cfg.RemoveKeys)),
+		LevelNames:   make(map[string]string, len(cfg.LevelNames)),
+		ContextKeys:  append([]string{}, cfg.ContextKeys...),
+		LoggerLevels: make(map[string]string, len(cfg.LoggerLevels)),
+	}
+	for k, t := range cfg.MaskKeys {
+		v.MaskKeys[k] = t
+	}
+	for k := range cfg.RemoveKeys {
+		v.RemoveKeys = append(v.RemoveKeys, k)
+	}
+	sort.Strings(v.RemoveKeys)
+	for l, name := range cfg.LevelNames {
+		v.LevelNames[l.String()] = name
+	}
+	for name, l := range cfg.LoggerLevels {
+		v.LoggerLevels[name] = getLevelName(l, cfg.LevelNames)
+	}
+	return v
+}
+
+// adminPatch is the body of a PATCH request. It follows JSON merge patch semantics
+// (RFC 7396): omitted fields are left alone, objects are merged with null deleting
+// an entry, and arrays replace the current value.
+type adminPatch struct {
+	// Version, if set, must match the current configuration like the If-Match header.
+	Version      *uint64              `json:"version"`
+	Level        *string              `json:"level"`
+	Format       *Format              `json:"format"`
+	MaskKeys     map[string]*MaskType `json:"mask_keys"`
+	RemoveKeys   *[]string            `json:"remove_keys"`
+	LevelNames   map[string]*string   `json:"level_names"`
+	ContextKeys  *[]string            `json:"context_keys"`
+	LoggerLevels map[string]*string   `json:"logger_levels"`
+}
+
+// configPatch is a validated adminPatch with all levels resolved. Applying it cannot fail.
+type configPatch struct {
+	level        *slog.Level
+	format       *Format
+	maskKeys     map[string]*MaskType
+	removeKeys   *[]string
+	levelNames   map[slog.Level]*string
+	contextKeys  *[]string
+	loggerLevels map[string]*slog.Level
+}
+
+// resolve validates p against cfg and resolves level names.
+func (p *adminPatch) resolve(cfg *Config) (*configPatch, error) {
+	cp := &configPatch{
+		format:      p.Format,
+		maskKeys:    p.MaskKeys,
+		removeKeys:  p.RemoveKeys,
+		contextKeys: p.ContextKeys,
+	}
+
+	// Level names are resolved first so that the other fields may use the new names
+	names := make(LevelNames, len(cfg.LevelNames)+len(p.LevelNames))
+	for l, name := range cfg.LevelNames {
+		names[l] = name
+	}
+	if p.LevelNames != nil {
+		cp.levelNames = make(map[slog.Level]*string, len(p.LevelNames))
+		for key, name := range p.LevelNames {
+			l, err := ParseLevel(key, cfg.LevelNames)
+			if err != nil {
+				return nil, fmt.Errorf("level_names: %w", err)
+			}
+			if name != nil && strings.TrimSpace(*name) == "" {
+				return nil, fmt.Errorf("level_names: empty name for %q", key)
+			}
+			cp.levelNames[l] = name
+			if name != nil {
+				names[l] = *name
+			}
+		}
+	}
+
+	if p.Level != nil {
+		l, err := ParseLevel(*p.Level, names)
+		if err != nil {
+			return nil, fmt.Errorf("level: %w", err)
+		}
+		cp.level = &l
+	}
+
+	if p.LoggerLevels != nil {
+		cp.loggerLevels = make(map[string]*slog.Level, len(p.LoggerLevels))
+		for name, s := range p.LoggerLevels {
+			if name == "" {
+				return nil, fmt.Errorf("logger_levels: empty logger name")
+			}
+			if s == nil {
+				cp.loggerLevels[name] = nil
+				continue
+			}
+			l, err := ParseLevel(*s, names)
+			if err != nil {
+				return nil, fmt.Errorf("logger_levels.%s: %w", name, err)
+			}
+			cp.loggerLevels[name] = &l
+		}
+	}
+
+	for key := range p.MaskKeys {
+		if key == "" {
+			return nil, fmt.Errorf("mask_keys: empty key")
+		}
+	}
+	for _, keys := range []*[]string{p.RemoveKeys, p.ContextKeys} {
+		if keys == nil {
+			continue
+		}
+		for _, key := range *keys {
+			if key == "" {
+				return nil, fmt.Errorf("empty key in remove_keys or context_keys")
+			}
+		}
+	}
+
+	return cp, nil
+}
+
+// apply writes the patch into c.
+func (p *configPatch) apply(c *Config) {
+	if p.level != nil {
+		c.Level = *p.level
+	}
+	if p.format != nil {
+		c.Format = *p.format
+	}
+	for key, t := range p.maskKeys {
+		if t == nil {
+			delete(c.MaskKeys, key)
+		} else {
+			c.MaskKeys[key] = *t
+		}
+	}
+	if p.removeKeys != nil {
+		c.RemoveKeys = make(RemoveMap, len(*p.removeKeys))
+		for _, key := range *p.removeKeys {
+			c.RemoveKeys[key] = struct{}{}
+		}
+	}
+	for l, name := range p.levelNames {
+		if name == nil {
+			delete(c.LevelNames, l)
+		} else {
+			c.LevelNames[l] = *name
+		}
+	}
+	if p.contextKeys != nil {
+		c.ContextKeys = append([]string(nil), *p.contextKeys...)
+	}
+	for name, l := range p.loggerLevels {
+		if l == nil {
+			delete(c.LoggerLevels, name)
+		} else {
+			c.LoggerLevels[name] = *l
+		}
+	}
+}
+
+// changes describes the patch for the audit log, e.g. ["level=DEBUG", "mask_keys.email=email"].
+func (p *configPatch) changes(names LevelNames) []string {
+	var out []string
+	if p.level != nil {
+		out = append(out, "level="+getLevelName(*p.level, names))
+	}
+	if p.format != nil {
+		out = append(out, "format="+p.format.String())
+	}
+	for key, t := range p.maskKeys {
+		v := "null"
+		if t != nil {
+			v = t.String()
+		}
+		out = append(out, "mask_keys."+key+"="+v)
+	}
+	if p.removeKeys != nil {
+		out = append(out, "remove_keys=["+strings.Join(*p.removeKeys, ",")+"]")
+	}
+	for l, name := range p.levelNames {
+		v := "null"
+		if name != nil {
+			v = *name
+		}
+		out = append(out, "level_names."+l.String()+"="+v)
+	}
+	if p.contextKeys != nil {
+		out = append(out, "context_keys=["+strings.Join(*p.contextKeys, ",")+"]")
+	}
+	for name, l := range p.loggerLevels {
+		v := "null"
+		if l != nil {
+			v = getLevelName(*l, names)
+		}
+		out = append(out, "logger_levels."+name+"="+v)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// adminHandler serves the runtime configuration of a Logger over HTTP.
+type adminHandler struct {
+	l    *Logger
+	opts adminOptions
+}
+
+// AdminHandler returns an http.Handler exposing the runtime configuration of the
+// logger: level, format, mask and remove keys, level names, context keys and
+// logger levels.
+//
+//   - GET returns the current configuration as JSON.
+//   - PATCH changes it. The body is a JSON merge patch of the GET document, e.g.
+//     {"level":"debug","mask_keys":{"email":"email","phone":null}}.
+//     With ?ttl=10m the patch is a temporary override (see Logger.UpdateConfigFor)
+//     reverted after the duration. The response is the resulting configuration.
+//     An If-Match header (or "version" field) with the version from a previous
+//     response makes the change conditional: a concurrent change yields 412.
+//     A patch that would make the configuration invalid (see Config.Validate) yields 400;
+//     the warnings of the resulting configuration (see Config.Warnings) are audited.
+//
+// Every request is authenticated with WithAdminAuth or WithAdminToken; without one,
+// every request is refused. Every change and every revert on expiry is written to
+// the audit log: by default the managed logger named "slogx.admin", at Info level
+// whatever the configured level is.
+func (l *Logger) AdminHandler(opts ...AdminOption) http.Handler {
+	h := &adminHandler{l: l}
+	for _, fn := range opts {
+		if fn != nil {
+			fn(&h.opts)
+		}
+	}
+	if h.opts.audit == nil {
+		named := l.Named("slogx.admin")
+		h.opts.audit = named.Logger
+		if dh, ok := named.Handler().(*DynamicHandler); ok {
+			h.opts.audit = slog.New(auditHandler{dh})
+		}
+	}
+	return h
+}
+
+// auditHandler writes the audit log through a DynamicHandler without the level
+// filter, sampling or deduplication: a change that raises the level must still be
+// recorded.
+type auditHandler struct {
+	h *DynamicHandler
+}
+
+func (a auditHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (a auditHandler) Handle(ctx context.Context, r slog.Record) error {
+	return a.h.handle(ctx, r)
+}
+
+func (a auditHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return auditHandler{a.h.WithAttrs(attrs).(*DynamicHandler)}
+}
+
+func (a auditHandler) WithGroup(name string) slog.Handler {
+	return auditHandler{a.h.WithGroup(name).(*DynamicHandler)}
+}
+
+func (h *adminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.opts.auth == nil {
+		adminError(w, http.StatusForbidden, "the admin handler requires WithAdminAuth or WithAdminToken")
+		return
+	}
+	principal, ok := h.opts.auth(r)
+	if !ok {
+		adminError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		h.writeConfig(w)
+	case http.MethodPatch:
+		h.patch(w, r, principal)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PATCH")
+		adminError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (h *adminHandler) patch(w http.ResponseWriter, r *http.Request, principal string) {
+	var ttl time.Duration
+	if s := r.URL.Query().Get("ttl"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			adminError(w, http.StatusBadRequest, fmt.Sprintf("invalid ttl %q", s))
+			return
+		}
+		ttl = d
+	}
+
+	var p adminPatch
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAdminBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		adminError(w, http.StatusBadRequest, "invalid body: "+err.Error())
+		return
+	}
+
+	cp, err := p.resolve(h.l.cfgPtr.Load())
+	if err != nil {
+		adminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	version := p.Version
+	if match := strings.Trim(r.Header.Get("If-Match"), `"`); match != "" {
+		v, err := strconv.ParseUint(match, 10, 64)
+		if err != nil {
+			adminError(w, http.StatusBadRequest, fmt.Sprintf("invalid If-Match %q", match))
+			return
+		}
+		version = &v
+	}
+
+	var o *Override
+	if ttl > 0 {
+		o, err = h.l.startOverride(version, true, cp.apply, ttl)
+	} else {
+		err = h.l.update(version, true, cp.apply)
+	}
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrConfigConflict) {
+			status = http.StatusPreconditionFailed
+		}
+		adminError(w, status, err.Error())
+		return
+	}
+
+	cfg := h.l.cfgPtr.Load()
+	changes := cp.changes(cfg.LevelNames)
+	attrs := []slog.Attr{
+		slog.String("by", principal),
+		slog.String("remote", r.RemoteAddr),
+		slog.Any("changes", changes),
+	}
+	if warnings := cfg.Warnings(); len(warnings) > 0 {
+		attrs = append(attrs, slog.Any("warnings", warnings))
+	}
+	if o != nil {
+		attrs = append(attrs, slog.Duration("ttl", ttl))
+		go func() {
+			<-o.Done()
+			if o.expired {
+				h.opts.audit.LogAttrs(
+					context.Background(), slog.LevelInfo, "logger config reverted",
+					slog.String("by", principal), slog.Any("changes", changes), slog.String("reason", "ttl expired"),
+				)
+			}
+		}()
+	}
+	h.opts.audit.LogAttrs(r.Context(), slog.LevelInfo, "logger config changed", attrs...)
+
+	h.writeConfig(w)
+}
+
+func (h *adminHandler) writeConfig(w http.ResponseWriter) {
+	cfg := h.l.cfgPtr.Load()
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", strconv.Quote(strconv.FormatUint(cfg.Version, 10)))
+	_ = json.NewEncoder(w).Encode(newAdminConfig(cfg))
+}
+
+func adminError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
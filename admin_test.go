@@ -0,0 +1,246 @@
+package slogx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer that may be read while another goroutine writes to it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func adminRequest(t *testing.T, h http.Handler, method, target, token, body string) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &m), rec.Body.String())
+	return rec, m
+}
+
+func TestAdminHandler(t *testing.T) {
+	buf, audit := &bytes.Buffer{}, &bytes.Buffer{}
+	l := New(
+		WithOutput(buf),
+		WithLevel(slog.LevelInfo),
+		WithMaskKey("email", MaskEmail),
+		WithRemoval(NewRemovalSet().Add("password")),
+	)
+	h := l.AdminHandler(
+		WithAdminToken("s3cret"),
+		WithAdminAuditLogger(slog.New(slog.NewJSONHandler(audit, nil))),
+	)
+
+	rec, m := adminRequest(t, h, http.MethodGet, "/", "s3cret", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "INFO", m["level"])
+	assert.Equal(t, "text", m["format"])
+	assert.Equal(t, map[string]any{"email": "email"}, m["mask_keys"])
+	assert.Equal(t, []any{"password"}, m["remove_keys"])
+	assert.Equal(t, "TRACE", m["level_names"].(map[string]any)["DEBUG-4"])
+
+	rec, _ = adminRequest(t, h, http.MethodGet, "/", "wrong", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec, m = adminRequest(
+		t, h, http.MethodPatch, "/", "s3cret",
+		`{"level":"trace","format":"json","mask_keys":{"email":null,"card":"card"},"logger_levels":{"db":"debug"}}`,
+	)
+	require.Equal(t, http.StatusOK, rec.Code, m)
+	assert.Equal(t, "TRACE", m["level"])
+	assert.Equal(t, "json", m["format"])
+	assert.Equal(t, map[string]any{"card": "card"}, m["mask_keys"])
+	assert.Equal(t, map[string]any{"db": "DEBUG"}, m["logger_levels"])
+
+	cfg := l.cfgPtr.Load()
+	assert.Equal(t, LevelTrace, cfg.Level)
+	assert.Equal(t, FormatJSON, cfg.Format)
+	assert.Equal(t, MaskCard, cfg.MaskKeys["card"])
+	assert.NotContains(t, cfg.MaskKeys, "email")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(audit.Bytes(), &entry))
+	assert.Equal(t, "logger config changed", entry["msg"])
+	assert.Equal(t, "token", entry["by"])
+	assert.Contains(t, entry["changes"], "level=TRACE")
+	assert.Contains(t, entry["changes"], "mask_keys.email=null")
+}
+
+func TestAdminHandler_Validation(t *testing.T) {
+	l := New(WithOutput(&bytes.Buffer{}))
+	h := l.AdminHandler(WithAdminToken("t"))
+	before := l.cfgPtr.Load()
+
+	for name, body := range map[string]string{
+		"unknown level":   `{"level":"verbose"}`,
+		"unknown format":  `{"format":"yaml"}`,
+		"unknown mask":    `{"mask_keys":{"a":"nope"}}`,
+		"unknown field":   `{"output":"stderr"}`,
+		"empty key":       `{"remove_keys":[""]}`,
+		"malformed":       `{"level":`,
+		"mask and remove": `{"mask_keys":{"x":"default"},"remove_keys":["x"]}`,
+	} {
+		t.Run(
+			name, func(t *testing.T) {
+				rec, m := adminRequest(t, h, http.MethodPatch, "/", "t", body)
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+				assert.NotEmpty(t, m["error"])
+			},
+		)
+	}
+	assert.Same(t, before, l.cfgPtr.Load(), "rejected patches must not change the config")
+
+	rec, _ := adminRequest(t, h, http.MethodPatch, "/?ttl=soon", "t", `{"level":"debug"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec, _ = adminRequest(t, h, http.MethodDelete, "/", "t", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	noAuth := l.AdminHandler(WithAdminAuditLogger(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))))
+	rec, _ = adminRequest(t, noAuth, http.MethodPatch, "/", "", `{"level":"debug"}`)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	rec, _ = adminRequest(t, noAuth, http.MethodGet, "/", "", "")
+	assert.Equal(t, http.StatusForbidden, rec.Code, "reads require authentication too")
+}
+
+func TestAdminHandler_DefaultAudit(t *testing.T) {
+	out := &syncBuffer{}
+	l := New(WithOutput(out), WithFormat(FormatJSON), WithLevel(slog.LevelInfo))
+	h := l.AdminHandler(WithAdminToken("t"))
+
+	rec, _ := adminRequest(t, h, http.MethodPatch, "/", "t", `{"level":"error"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	lines := jsonLines(t, out.String())
+	require.Len(t, lines, 1, "the audit log ignores the level it just raised")
+	assert.Equal(t, "logger config changed", lines[0]["msg"])
+	assert.Equal(t, "slogx.admin", lines[0][LoggerKey])
+
+	// A patch that removes a masked key is applied, with a warning
+	rec, _ = adminRequest(t, h, http.MethodPatch, "/", "t", `{"mask_keys":{"email":"email"},"remove_keys":["user.email"]}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	lines = jsonLines(t, out.String())
+	require.Len(t, lines, 2)
+	assert.Equal(
+		t, []any{`mask rule "email" overlaps remove rule "user.email": the key is removed`}, lines[1]["warnings"],
+	)
+
+	// An override cancelled before its ttl is not reported as expired
+	rec, _ = adminRequest(t, h, http.MethodPatch, "/?ttl=1h", "t", `{"level":"debug"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	l.state.cfgMu.Lock()
+	o := l.state.overrides[0]
+	l.state.cfgMu.Unlock()
+	require.True(t, o.Cancel())
+	time.Sleep(20 * time.Millisecond)
+	assert.NotContains(t, out.String(), "logger config reverted")
+	assert.False(t, o.expired)
+}
+
+func TestAdminHandler_TTL(t *testing.T) {
+	audit := &syncBuffer{}
+	l := New(WithOutput(&bytes.Buffer{}), WithLevel(slog.LevelInfo), WithRemoval(NewRemovalSet().Add("password")))
+	h := l.AdminHandler(
+		WithAdminAuth(func(r *http.Request) (string, bool) { return "alice", true }),
+		WithAdminAuditLogger(slog.New(slog.NewTextHandler(audit, nil))),
+	)
+
+	rec, _ := adminRequest(t, h, http.MethodPatch, "/?ttl=50ms", "", `{"level":"debug","remove_keys":[]}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, slog.LevelDebug, l.cfgPtr.Load().Level)
+
+	// A change made meanwhile to a field the patch did not touch survives the revert
+	l.UpdateConfig(func(c *Config) { c.Format = FormatJSON })
+
+	require.Eventually(
+		t, func() bool { return l.cfgPtr.Load().Level == slog.LevelInfo },
+		2*time.Second, 5*time.Millisecond,
+	)
+	cfg := l.cfgPtr.Load()
+	assert.Contains(t, cfg.RemoveKeys, "password")
+	assert.Equal(t, FormatJSON, cfg.Format)
+	require.Eventually(
+		t, func() bool { return strings.Contains(audit.String(), "logger config reverted") },
+		time.Second, 5*time.Millisecond,
+	)
+	assert.Contains(t, audit.String(), "by=alice")
+}
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]slog.Level{
+		"trace":  LevelTrace,
+		"FATAL":  LevelFatal,
+		"debug":  slog.LevelDebug,
+		"info+2": slog.LevelInfo + 2,
+		"-3":     slog.Level(-3),
+		"AUDIT":  slog.Level(10),
+	} {
+		l, err := ParseLevel(in, LevelNames{slog.Level(10): "audit"})
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, l, in)
+	}
+	_, err := ParseLevel("verbose", nil)
+	assert.Error(t, err)
+}
+
+func TestAdminHandler_Version(t *testing.T) {
+	l := New(WithOutput(&bytes.Buffer{}), WithLevel(slog.LevelInfo))
+	h := l.AdminHandler(
+		WithAdminToken("t"),
+		WithAdminAuditLogger(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))),
+	)
+
+	rec, m := adminRequest(t, h, http.MethodGet, "/", "t", "")
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, etag, `"`+json.Number(fmt.Sprint(m["version"])).String()+`"`)
+
+	// Another admin changes the config meanwhile
+	l.SetLevel(slog.LevelWarn)
+
+	req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"level":"debug"}`))
+	req.Header.Set("Authorization", "Bearer t")
+	req.Header.Set("If-Match", etag)
+	stale := httptest.NewRecorder()
+	h.ServeHTTP(stale, req)
+	assert.Equal(t, http.StatusPreconditionFailed, stale.Code)
+	assert.Equal(t, slog.LevelWarn, l.cfgPtr.Load().Level)
+
+	rec, _ = adminRequest(t, h, http.MethodGet, "/", "t", "")
+	req = httptest.NewRequest(http.MethodPatch, "/?ttl=1h", strings.NewReader(`{"level":"debug"}`))
+	req.Header.Set("Authorization", "Bearer t")
+	req.Header.Set("If-Match", rec.Header().Get("ETag"))
+	fresh := httptest.NewRecorder()
+	h.ServeHTTP(fresh, req)
+	assert.Equal(t, http.StatusOK, fresh.Code)
+	assert.Equal(t, slog.LevelDebug, l.cfgPtr.Load().Level)
+}
@@ -0,0 +1,286 @@
+package slogx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrWriterClosed is returned by writes to an AsyncWriter after Close.
+var ErrWriterClosed = errors.New("slogx: writer is closed")
+
+// Flusher is implemented by outputs that buffer records, such as AsyncWriter.
+// Logger.Flush calls it on every configured output.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// OverflowPolicy decides what an AsyncWriter does when its buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes the logging goroutine wait until there is room in the buffer.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest discards the record being written.
+	OverflowDropNewest
+	// OverflowDropOldest discards the oldest buffered record to make room.
+	OverflowDropOldest
+	// OverflowSample keeps every Nth record (see WithSampleRate) by blocking for it
+	// and discards the others while the buffer is full.
+	OverflowSample
+)
+
+const (
+	defaultAsyncBufferSize = 1024
+	defaultAsyncSampleRate = 10
+)
+
+// AsyncStats is a snapshot of AsyncWriter counters.
+type AsyncStats struct {
+	// Written is the number of records passed to the wrapped writer.
+	Written uint64
+	// Dropped is the number of records discarded by the overflow policy.
+	Dropped uint64
+	// Errors is the number of failed writes to the wrapped writer.
+	Errors uint64
+	// Queued is the number of records currently waiting in the buffer.
+	Queued int
+}
+
+// asyncOptions holds AsyncWriter settings.
+type asyncOptions struct {
+	size       int
+	policy     OverflowPolicy
+	sampleRate int
+}
+
+// AsyncOption is a functional configuration parameter for NewAsyncWriter.
+type AsyncOption func(*asyncOptions)
+
+// WithBufferSize sets the capacity of the ring buffer in records.
+func WithBufferSize(n int) AsyncOption {
+	return func(o *asyncOptions) {
+		if n > 0 {
+			o.size = n
+		}
+	}
+}
+
+// WithOverflowPolicy sets what happens when the buffer is full.
+func WithOverflowPolicy(p OverflowPolicy) AsyncOption {
+	return func(o *asyncOptions) {
+		o.policy = p
+	}
+}
+
+// WithSampleRate sets N for OverflowSample: one of every N overflowing records is kept.
+func WithSampleRate(n int) AsyncOption {
+	return func(o *asyncOptions) {
+		if n > 0 {
+			o.sampleRate = n
+		}
+	}
+}
+
+// AsyncWriter decouples logging goroutines from a slow output. Records are copied
+// into a bounded ring buffer and written to the wrapped writer by a background
+// goroutine. Use it as Config.Output or Sink.Output and call Logger.Flush or
+// Logger.Close on shutdown so buffered records are not lost.
+//
+// AsyncWriter never closes the wrapped writer; it stays owned by the caller.
+type AsyncWriter struct {
+	w    io.Writer
+	opts asyncOptions
+
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	ring     [][]byte
+	head     int
+	count    int
+	writing  bool
+	closed   bool
+	overflow int
+	// idle is closed (and replaced) every time the buffer is fully drained.
+	idle chan struct{}
+	done chan struct{}
+
+	written atomic.Uint64
+	dropped atomic.Uint64
+	errs    atomic.Uint64
+}
+
+// NewAsyncWriter wraps w and starts the background flusher.
+func NewAsyncWriter(w io.Writer, opts ...AsyncOption) *AsyncWriter {
+	o := asyncOptions{
+		size:       defaultAsyncBufferSize,
+		policy:     OverflowBlock,
+		sampleRate: defaultAsyncSampleRate,
+	}
+	for _, fn := range opts {
+		if fn != nil {
+			fn(&o)
+		}
+	}
+
+	a := &AsyncWriter{
+		w:    w,
+		opts: o,
+		ring: make([][]byte, o.size),
+		idle: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	a.notEmpty = sync.NewCond(&a.mu)
+	a.notFull = sync.NewCond(&a.mu)
+
+	go a.run()
+	return a
+}
+
+// Write queues a copy of p. It only blocks if the buffer is full and the overflow
+// policy says so. Records discarded by the policy are counted but not reported as errors.
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	rec := append([]byte(nil), p...)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return 0, ErrWriterClosed
+	}
+
+	if a.count == len(a.ring) {
+		switch a.opts.policy {
+		case OverflowDropNewest:
+			a.dropped.Add(1)
+			return len(p), nil
+
+		case OverflowDropOldest:
+			a.ring[a.head] = nil
+			a.head = (a.head + 1) % len(a.ring)
+			a.count--
+			a.dropped.Add(1)
+
+		case OverflowSample:
+			a.overflow++
+			if a.overflow%a.opts.sampleRate != 0 {
+				a.dropped.Add(1)
+				return len(p), nil
+			}
+			if !a.waitNotFull() {
+				return 0, ErrWriterClosed
+			}
+
+		default:
+			if !a.waitNotFull() {
+				return 0, ErrWriterClosed
+			}
+		}
+	}
+
+	a.ring[(a.head+a.count)%len(a.ring)] = rec
+	a.count++
+	a.notEmpty.Signal()
+	return len(p), nil
+}
+
+// waitNotFull blocks until the buffer has room. It returns false if the writer was closed meanwhile.
+// a.mu must be held.
+func (a *AsyncWriter) waitNotFull() bool {
+	for a.count == len(a.ring) && !a.closed {
+		a.notFull.Wait()
+	}
+	return !a.closed
+}
+
+// run is the background flusher: it drains the buffer in batches.
+func (a *AsyncWriter) run() {
+	defer close(a.done)
+
+	var batch [][]byte
+	for {
+		a.mu.Lock()
+		for a.count == 0 && !a.closed {
+			a.notEmpty.Wait()
+		}
+		if a.count == 0 && a.closed {
+			a.mu.Unlock()
+			return
+		}
+
+		batch = batch[:0]
+		for a.count > 0 {
+			batch = append(batch, a.ring[a.head])
+			a.ring[a.head] = nil
+			a.head = (a.head + 1) % len(a.ring)
+			a.count--
+		}
+		a.writing = true
+		a.notFull.Broadcast()
+		a.mu.Unlock()
+
+		for _, rec := range batch {
+			if _, err := a.w.Write(rec); err != nil {
+				a.errs.Add(1)
+				continue
+			}
+			a.written.Add(1)
+		}
+
+		a.mu.Lock()
+		a.writing = false
+		if a.count == 0 {
+			close(a.idle)
+			a.idle = make(chan struct{})
+		}
+		a.mu.Unlock()
+	}
+}
+
+// Flush waits until the buffer has been drained and the wrapped writer has
+// received every queued record, or until ctx is done.
+func (a *AsyncWriter) Flush(ctx context.Context) error {
+	a.mu.Lock()
+	if a.count == 0 && !a.writing {
+		a.mu.Unlock()
+		return nil
+	}
+	idle := a.idle
+	a.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting records, drains the buffer and stops the background flusher.
+// It is safe to call Close more than once.
+func (a *AsyncWriter) Close() error {
+	a.mu.Lock()
+	a.closed = true
+	a.notEmpty.Broadcast()
+	a.notFull.Broadcast()
+	a.mu.Unlock()
+
+	<-a.done
+	return nil
+}
+
+// Stats returns a snapshot of the writer's counters.
+func (a *AsyncWriter) Stats() AsyncStats {
+	a.mu.Lock()
+	queued := a.count
+	a.mu.Unlock()
+
+	return AsyncStats{
+		Written: a.written.Load(),
+		Dropped: a.dropped.Load(),
+		Errors:  a.errs.Load(),
+		Queued:  queued,
+	}
+}
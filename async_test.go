@@ -0,0 +1,93 @@
+package slogx
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedWriter blocks every write until the gate is opened.
+type gatedWriter struct {
+	gate chan struct{}
+	mu   sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gatedWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncWriter_FlushAndClose(t *testing.T) {
+	out := &gatedWriter{gate: make(chan struct{})}
+	close(out.gate)
+
+	aw := NewAsyncWriter(out)
+	l := New(WithOutput(aw))
+
+	for i := 0; i < 100; i++ {
+		l.Info("async record")
+	}
+	require.NoError(t, l.Flush(context.Background()))
+	assert.Equal(t, 100, strings.Count(out.String(), "async record"))
+
+	require.NoError(t, l.Close())
+	_, err := aw.Write([]byte("late"))
+	assert.ErrorIs(t, err, ErrWriterClosed)
+	assert.Equal(t, uint64(100), aw.Stats().Written)
+}
+
+func TestAsyncWriter_OverflowPolicies(t *testing.T) {
+	for _, policy := range []OverflowPolicy{OverflowDropNewest, OverflowDropOldest, OverflowSample} {
+		out := &gatedWriter{gate: make(chan struct{})}
+		aw := NewAsyncWriter(out, WithBufferSize(4), WithOverflowPolicy(policy), WithSampleRate(1000))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 50; i++ {
+				_, _ = aw.Write([]byte("x\n"))
+			}
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("policy %d blocked the writer", policy)
+		}
+
+		stats := aw.Stats()
+		assert.Positive(t, stats.Dropped, "policy %d", policy)
+		close(out.gate)
+		require.NoError(t, aw.Flush(context.Background()))
+		require.NoError(t, aw.Close())
+	}
+}
+
+func TestAsyncWriter_FlushTimeout(t *testing.T) {
+	out := &gatedWriter{gate: make(chan struct{})}
+	aw := NewAsyncWriter(out)
+	_, _ = aw.Write([]byte("stuck\n"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, aw.Flush(ctx), context.DeadlineExceeded)
+
+	close(out.gate)
+	require.NoError(t, aw.Close())
+	assert.Contains(t, out.String(), "stuck")
+}
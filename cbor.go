@@ -0,0 +1,206 @@
+package slogx
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+)
+
+// CBOR major types (RFC 8949, section 3.1).
+const (
+	cborUint   byte = 0 << 5
+	cborNegInt byte = 1 << 5
+	cborBytes  byte = 2 << 5
+	cborText   byte = 3 << 5
+	cborArray  byte = 4 << 5
+	cborMap    byte = 5 << 5
+	cborTag    byte = 6 << 5
+	cborSimple byte = 7 << 5
+)
+
+const (
+	cborFalse   = cborSimple | 20
+	cborTrue    = cborSimple | 21
+	cborNull    = cborSimple | 22
+	cborFloat64 = cborSimple | 27
+	// cborTagEpoch marks a numeric timestamp in seconds since the Unix epoch.
+	cborTagEpoch = 1
+)
+
+// newCBORHandler returns the handler behind FormatCBOR.
+func newCBORHandler(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	return newEncodeHandler(w, opts, encodeCBOR)
+}
+
+// encodeCBOR renders a record as a single CBOR map with text keys. Times are
+// tagged epoch timestamps (tag 1), durations are integer nanoseconds, groups
+// are nested maps and arbitrary values are encoded through their JSON form.
+// Records are not delimited: the output is a CBOR sequence (RFC 8742).
+func encodeCBOR(buf []byte, e *entry) []byte {
+	n := len(e.attrs)
+	for _, a := range []slog.Attr{e.time, e.lvl, e.msg} {
+		if a.Key != "" {
+			n++
+		}
+	}
+
+	buf = appendCBORHead(buf, cborMap, uint64(n))
+	for _, a := range []slog.Attr{e.time, e.lvl, e.msg} {
+		if a.Key != "" {
+			buf = appendCBORAttr(buf, a)
+		}
+	}
+	for _, a := range e.attrs {
+		buf = appendCBORAttr(buf, a)
+	}
+	return buf
+}
+
+func appendCBORAttr(buf []byte, a slog.Attr) []byte {
+	buf = appendCBORText(buf, a.Key)
+	return appendCBORValue(buf, a.Value)
+}
+
+func appendCBORValue(buf []byte, v slog.Value) []byte {
+	switch v.Kind() {
+	case slog.KindString:
+		return appendCBORText(buf, v.String())
+	case slog.KindInt64:
+		return appendCBORInt(buf, v.Int64())
+	case slog.KindUint64:
+		return appendCBORHead(buf, cborUint, v.Uint64())
+	case slog.KindFloat64:
+		return appendCBORFloat(buf, v.Float64())
+	case slog.KindBool:
+		return appendCBORBool(buf, v.Bool())
+	case slog.KindDuration:
+		return appendCBORInt(buf, int64(v.Duration()))
+	case slog.KindTime:
+		return appendCBORTime(buf, v.Time())
+	case slog.KindGroup:
+		attrs := v.Group()
+		buf = appendCBORHead(buf, cborMap, uint64(len(attrs)))
+		for _, a := range attrs {
+			buf = appendCBORAttr(buf, a)
+		}
+		return buf
+	default:
+		return appendCBORAny(buf, v.Any())
+	}
+}
+
+// appendCBORAny encodes values of unknown type: errors and Stringers as text,
+// byte slices as byte strings, anything else through its JSON representation.
+func appendCBORAny(buf []byte, v any) []byte {
+	switch x := v.(type) {
+	case nil:
+		return append(buf, cborNull)
+	case []byte:
+		buf = appendCBORHead(buf, cborBytes, uint64(len(x)))
+		return append(buf, x...)
+	case error:
+		return appendCBORText(buf, x.Error())
+	case time.Time:
+		return appendCBORTime(buf, x)
+	case fmt.Stringer:
+		if _, isJSON := v.(json.Marshaler); !isJSON {
+			return appendCBORText(buf, x.String())
+		}
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return appendCBORText(buf, fmt.Sprintf("%+v", v))
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return appendCBORText(buf, string(data))
+	}
+	return appendCBORGeneric(buf, generic)
+}
+
+// appendCBORGeneric encodes the value tree produced by json.Unmarshal into an any.
+func appendCBORGeneric(buf []byte, v any) []byte {
+	switch x := v.(type) {
+	case nil:
+		return append(buf, cborNull)
+	case bool:
+		return appendCBORBool(buf, x)
+	case float64:
+		if x == math.Trunc(x) && math.Abs(x) < 1<<53 {
+			return appendCBORInt(buf, int64(x))
+		}
+		return appendCBORFloat(buf, x)
+	case string:
+		return appendCBORText(buf, x)
+	case []any:
+		buf = appendCBORHead(buf, cborArray, uint64(len(x)))
+		for _, item := range x {
+			buf = appendCBORGeneric(buf, item)
+		}
+		return buf
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf = appendCBORHead(buf, cborMap, uint64(len(x)))
+		for _, k := range keys {
+			buf = appendCBORText(buf, k)
+			buf = appendCBORGeneric(buf, x[k])
+		}
+		return buf
+	default:
+		return appendCBORText(buf, fmt.Sprint(v))
+	}
+}
+
+// appendCBORHead appends the initial byte(s) of an item of the given major type and argument.
+func appendCBORHead(buf []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(buf, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(buf, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, major|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, major|27), n)
+	}
+}
+
+func appendCBORInt(buf []byte, n int64) []byte {
+	if n >= 0 {
+		return appendCBORHead(buf, cborUint, uint64(n))
+	}
+	return appendCBORHead(buf, cborNegInt, uint64(-(n + 1)))
+}
+
+func appendCBORFloat(buf []byte, f float64) []byte {
+	return binary.BigEndian.AppendUint64(append(buf, cborFloat64), math.Float64bits(f))
+}
+
+func appendCBORBool(buf []byte, b bool) []byte {
+	if b {
+		return append(buf, cborTrue)
+	}
+	return append(buf, cborFalse)
+}
+
+func appendCBORText(buf []byte, s string) []byte {
+	buf = appendCBORHead(buf, cborText, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func appendCBORTime(buf []byte, t time.Time) []byte {
+	buf = appendCBORHead(buf, cborTag, cborTagEpoch)
+	return appendCBORFloat(buf, float64(t.UnixNano())/1e9)
+}
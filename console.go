@@ -0,0 +1,264 @@
+package slogx
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// consoleTimeFormat is the short timestamp used by FormatConsole.
+	consoleTimeFormat = "15:04:05.000"
+	// consoleLevelWidth pads level names so that messages start in the same column.
+	consoleLevelWidth = 5
+	// consoleMessageWidth pads messages so that attributes start in the same column.
+	consoleMessageWidth = 40
+	// consoleBlockIndent indents multi-line values rendered below the record line.
+	consoleBlockIndent = "    "
+)
+
+// ANSI escape sequences used by FormatConsole.
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiDim     = "\x1b[2m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiYellow  = "\x1b[33m"
+	ansiBlue    = "\x1b[34m"
+	ansiMagenta = "\x1b[35m"
+	ansiCyan    = "\x1b[36m"
+)
+
+// newConsoleHandler returns the handler behind FormatConsole. Colors are enabled
+// only if w is a terminal and the NO_COLOR environment variable is not set.
+func newConsoleHandler(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	c := &consoleEncoder{color: colorEnabled(w)}
+	return newEncodeHandler(w, opts, c.encode)
+}
+
+// colorEnabled reports whether ANSI colors should be written to w (see https://no-color.org).
+func colorEnabled(w io.Writer) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	// Look through wrappers such as the per-output lock added by the logger
+	for {
+		u, ok := w.(interface{ Unwrap() io.Writer })
+		if !ok {
+			break
+		}
+		w = u.Unwrap()
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// consoleEncoder renders records for humans:
+//
+//	15:04:05.000 INFO  server started                           addr=:8080 env=dev
+//	15:04:05.120 ERROR request failed                           status=500
+//	    error: dial tcp: connection refused
+//
+// Errors and multi-line values (e.g. stack traces) are printed below the line.
+type consoleEncoder struct {
+	color bool
+}
+
+func (c *consoleEncoder) encode(buf []byte, e *entry) []byte {
+	if e.time.Key != "" {
+		if t, ok := timeValue(e.time); ok {
+			buf = c.paint(buf, ansiDim, t.Format(consoleTimeFormat))
+		} else {
+			buf = c.paint(buf, ansiDim, e.time.Value.String())
+		}
+		buf = append(buf, ' ')
+	}
+
+	if e.lvl.Key != "" {
+		name := e.lvl.Value.String()
+		if n := utf8.RuneCountInString(name); n < consoleLevelWidth {
+			name += strings.Repeat(" ", consoleLevelWidth-n)
+		}
+		buf = c.paint(buf, levelColor(e.level), name)
+		buf = append(buf, ' ')
+	}
+
+	var blocks []consoleBlock
+	var inline []byte
+	for _, a := range e.attrs {
+		inline, blocks = c.appendAttr(inline, blocks, "", a)
+	}
+
+	if e.msg.Key != "" {
+		msg := e.msg.Value.String()
+		if strings.Contains(msg, "\n") {
+			lines := strings.SplitN(msg, "\n", 2)
+			msg = lines[0]
+			blocks = append([]consoleBlock{{text: lines[1]}}, blocks...)
+		}
+		if e.level >= slog.LevelError {
+			buf = c.paint(buf, ansiBold, msg)
+		} else {
+			buf = append(buf, msg...)
+		}
+		if len(inline) > 0 {
+			if n := utf8.RuneCountInString(msg); n < consoleMessageWidth {
+				buf = append(buf, strings.Repeat(" ", consoleMessageWidth-n)...)
+			}
+		}
+	}
+
+	if len(inline) > 0 {
+		buf = append(buf, ' ')
+		buf = append(buf, inline[1:]...)
+	}
+	buf = append(buf, '\n')
+
+	for _, b := range blocks {
+		buf = c.appendBlock(buf, b)
+	}
+	return buf
+}
+
+// consoleBlock is a value rendered on its own lines below the record.
+type consoleBlock struct {
+	key  string
+	text string
+	err  bool
+}
+
+// appendAttr appends " key=value" to inline, or collects the attribute as a block
+// if it is an error or spans several lines. Groups are flattened with dots.
+func (c *consoleEncoder) appendAttr(inline []byte, blocks []consoleBlock, prefix string, a slog.Attr) ([]byte, []consoleBlock) {
+	key := prefix + a.Key
+
+	if a.Value.Kind() == slog.KindGroup {
+		for _, m := range a.Value.Group() {
+			inline, blocks = c.appendAttr(inline, blocks, key+pathSep, m)
+		}
+		return inline, blocks
+	}
+
+	if a.Value.Kind() == slog.KindAny {
+		if err, ok := a.Value.Any().(error); ok {
+			return inline, append(blocks, consoleBlock{key: key, text: fmt.Sprintf("%+v", err), err: true})
+		}
+	}
+
+	val := consoleValue(a.Value)
+	if strings.Contains(val, "\n") {
+		return inline, append(blocks, consoleBlock{key: key, text: val})
+	}
+
+	inline = append(inline, ' ')
+	inline = c.paint(inline, ansiCyan, key)
+	inline = c.paint(inline, ansiDim, "=")
+	inline = append(inline, quoteIfNeeded(val)...)
+	return inline, blocks
+}
+
+func (c *consoleEncoder) appendBlock(buf []byte, b consoleBlock) []byte {
+	lines := strings.Split(strings.TrimRight(b.text, "\n"), "\n")
+
+	if b.key == "" {
+		for _, l := range lines {
+			buf = append(buf, consoleBlockIndent...)
+			buf = append(buf, l...)
+			buf = append(buf, '\n')
+		}
+		return buf
+	}
+
+	keyColor := ansiCyan
+	if b.err {
+		keyColor = ansiRed
+	}
+	buf = append(buf, consoleBlockIndent...)
+	buf = c.paint(buf, keyColor, b.key+":")
+	if len(lines) == 1 {
+		buf = append(buf, ' ')
+		buf = append(buf, lines[0]...)
+		return append(buf, '\n')
+	}
+
+	buf = append(buf, '\n')
+	for _, l := range lines {
+		buf = append(buf, consoleBlockIndent...)
+		buf = append(buf, consoleBlockIndent...)
+		buf = c.paint(buf, ansiDim, l)
+		buf = append(buf, '\n')
+	}
+	return buf
+}
+
+// paint appends s wrapped in the color sequence if colors are enabled.
+func (c *consoleEncoder) paint(buf []byte, color, s string) []byte {
+	if !c.color {
+		return append(buf, s...)
+	}
+	buf = append(buf, color...)
+	buf = append(buf, s...)
+	return append(buf, ansiReset...)
+}
+
+// levelColor picks a color by level range, so custom levels (and custom
+// LevelNames such as TRACE or FATAL) are colored like their neighbours.
+func levelColor(l slog.Level) string {
+	switch {
+	case l < slog.LevelDebug:
+		return ansiMagenta
+	case l < slog.LevelInfo:
+		return ansiBlue
+	case l < slog.LevelWarn:
+		return ansiGreen
+	case l < slog.LevelError:
+		return ansiYellow
+	case l < LevelFatal:
+		return ansiRed
+	default:
+		return ansiBold + ansiRed
+	}
+}
+
+// consoleValue renders a single attribute value as text.
+func consoleValue(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindAny:
+		if s, ok := v.Any().(fmt.Stringer); ok {
+			return s.String()
+		}
+		return fmt.Sprintf("%+v", v.Any())
+	default:
+		return v.String()
+	}
+}
+
+// quoteIfNeeded quotes values that would otherwise be ambiguous in key=value output.
+func quoteIfNeeded(s string) string {
+	if s == "" {
+		return `""`
+	}
+	for _, r := range s {
+		if r == ' ' || r == '=' || r == '"' || r < 0x20 || r == 0x7f {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
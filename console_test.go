@@ -0,0 +1,64 @@
+package slogx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsoleFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(WithOutput(buf), WithFormat(FormatConsole), WithMaskKey("email", MaskEmail))
+
+	l.With("svc", "api").WithGroup("req").Info("handled", "status", 200, "email", "antonioh@gmail.com")
+	line := buf.String()
+	assert.NotContains(t, line, "\x1b[", "colors must be off for non-TTY output")
+	assert.Regexp(t, `^\d{2}:\d{2}:\d{2}\.\d{3} INFO  handled {34}svc=api req\.status=200 req\.email=an\*\*\*h@gmail\.com\n$`, line)
+	buf.Reset()
+
+	l.TraceContext(context.Background(), "custom level")
+	assert.Contains(t, buf.String(), " TRACE custom level")
+	buf.Reset()
+
+	l.Error("request failed", slog.Any("error", errors.New("dial tcp: refused")), "stack", "main.main()\n\tmain.go:10")
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 5)
+	assert.Contains(t, lines[0], "ERROR request failed")
+	assert.Equal(t, "    error: dial tcp: refused", lines[1])
+	assert.Equal(t, "    stack:", lines[2])
+	assert.Equal(t, "        main.main()", lines[3])
+}
+
+func TestConsoleFormat_Colors(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := newEncodeHandler(buf, &slog.HandlerOptions{Level: LevelTrace}, (&consoleEncoder{color: true}).encode)
+
+	slog.New(h).Warn("careful", "k", "v")
+	assert.Contains(t, buf.String(), ansiYellow+"WARN "+ansiReset)
+	assert.Contains(t, buf.String(), ansiCyan+"k"+ansiReset)
+
+	t.Setenv("NO_COLOR", "1")
+	assert.False(t, colorEnabled(buf))
+}
+
+func TestConsoleFormat_TerminalBehindLock(t *testing.T) {
+	// /dev/null is a character device, like a terminal
+	f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	require.NoError(t, err)
+	defer f.Close()
+
+	t.Setenv("NO_COLOR", "")
+	require.NoError(t, os.Unsetenv("NO_COLOR"))
+
+	st := &loggerState{}
+	assert.True(t, colorEnabled(f))
+	assert.True(t, colorEnabled(st.lockedWriter(f)), "the output lock must not hide the terminal")
+	assert.False(t, colorEnabled(st.lockedWriter(&bytes.Buffer{})))
+}
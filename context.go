@@ -22,3 +22,132 @@ func FromContext(ctx context.Context) *Logger {
 func ToContext(ctx context.Context, l *Logger) context.Context {
 	return context.WithValue(ctx, ctxKey{}, l)
 }
+
+// ContextExtractor returns attributes derived from a context. Extractors registered
+// with WithContextExtractor run for every record logged with a *Context method.
+type ContextExtractor func(ctx context.Context) []slog.Attr
+
+// ContextKey is a typed context key that logs its value under Name. Unlike string
+// keys it cannot collide with keys of other packages:
+//
+//	var RequestID = slogx.NewContextKey[string]("request_id")
+//
+//	ctx = RequestID.WithValue(ctx, "req_777")
+//	log := slogx.New(slogx.WithContextExtractor(RequestID.Extract))
+type ContextKey[T any] struct {
+	name string
+}
+
+// NewContextKey creates a typed context key logged under name.
+func NewContextKey[T any](name string) *ContextKey[T] {
+	return &ContextKey[T]{name: name}
+}
+
+// Name returns the attribute key the value is logged under.
+func (k *ContextKey[T]) Name() string {
+	return k.name
+}
+
+// WithValue returns a copy of ctx carrying v under k.
+func (k *ContextKey[T]) WithValue(ctx context.Context, v T) context.Context {
+	return context.WithValue(ctx, k, v)
+}
+
+// Value returns the value stored under k, if any.
+func (k *ContextKey[T]) Value(ctx context.Context) (T, bool) {
+	v, ok := ctx.Value(k).(T)
+	return v, ok
+}
+
+// Extract is a ContextExtractor that logs the value stored under k.
+func (k *ContextKey[T]) Extract(ctx context.Context) []slog.Attr {
+	v, ok := k.Value(ctx)
+	if !ok {
+		return nil
+	}
+	return []slog.Attr{slog.Any(k.name, v)}
+}
+
+// ctxAttrsKey is the context key for attributes stashed with ContextWith.
+type ctxAttrsKey struct{}
+
+// ContextWith returns a copy of ctx carrying attrs in addition to the attributes
+// already stashed in it. They are added to every record logged with the context;
+// no extractor needs to be registered. An attribute replaces a stashed one with the
+// same key, so the most recently derived context wins. The parent context is not modified.
+func ContextWith(ctx context.Context, attrs ...slog.Attr) context.Context {
+	if len(attrs) == 0 {
+		return ctx
+	}
+	prev := ContextAttrs(ctx)
+	merged := make([]slog.Attr, len(prev), len(prev)+len(attrs))
+	copy(merged, prev)
+	for _, a := range attrs {
+		merged = setAttr(merged, a)
+	}
+	return context.WithValue(ctx, ctxAttrsKey{}, merged)
+}
+
+// AppendCtx is ContextWith for the loosely typed arguments of Logger.With:
+// alternating keys and values, or slog.Attr values.
+//
+//	ctx = slogx.AppendCtx(ctx, "order_id", id, slog.Int("items", n))
+func AppendCtx(ctx context.Context, args ...any) context.Context {
+	return ContextWith(ctx, argsToAttrs(args)...)
+}
+
+// ContextAttrs returns the attributes stashed in ctx with ContextWith.
+// The returned slice must not be modified.
+func ContextAttrs(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(ctxAttrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// ctxLevelKey is the context key for the level override set with ContextWithLevel.
+type ctxLevelKey struct{}
+
+// ContextWithLevel returns a copy of ctx in which records are logged at level and
+// above, regardless of Config.Level. Use it to debug a single request while the rest
+// of the service keeps its level; it can also raise the level, e.g. for health checks.
+// Sink levels still apply on top of it.
+func ContextWithLevel(ctx context.Context, level slog.Leveler) context.Context {
+	return context.WithValue(ctx, ctxLevelKey{}, level)
+}
+
+// LevelFromContext returns the level override set with ContextWithLevel, if any.
+func LevelFromContext(ctx context.Context) (slog.Level, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	l, ok := ctx.Value(ctxLevelKey{}).(slog.Leveler)
+	if !ok || l == nil {
+		return 0, false
+	}
+	return l.Level(), true
+}
+
+// setAttr replaces the attribute with the same key in attrs, or appends a.
+func setAttr(attrs []slog.Attr, a slog.Attr) []slog.Attr {
+	for i := range attrs {
+		if attrs[i].Key == a.Key {
+			attrs[i] = a
+			return attrs
+		}
+	}
+	return append(attrs, a)
+}
+
+// argsToAttrs converts Logger.With-style arguments to attributes using the same
+// rules as slog (a key without a value becomes "!BADKEY").
+func argsToAttrs(args []any) []slog.Attr {
+	var r slog.Record
+	r.Add(args...)
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(
+		func(a slog.Attr) bool {
+			attrs = append(attrs, a)
+			return true
+		},
+	)
+	return attrs
+}
@@ -0,0 +1,154 @@
+package slogx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextKey(t *testing.T) {
+	userID := NewContextKey[int]("user_id")
+	other := NewContextKey[int]("user_id")
+
+	ctx := userID.WithValue(context.Background(), 42)
+	v, ok := userID.Value(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, 42, v)
+
+	_, ok = other.Value(ctx)
+	assert.False(t, ok, "keys with the same name must not collide")
+	assert.Nil(t, other.Extract(ctx))
+	assert.Equal(t, []slog.Attr{slog.Any("user_id", 42)}, userID.Extract(ctx))
+}
+
+func TestContextExtractors(t *testing.T) {
+	requestID := NewContextKey[string]("request_id")
+	tenant := func(ctx context.Context) []slog.Attr {
+		return []slog.Attr{slog.String("tenant", "acme")}
+	}
+
+	buf := &bytes.Buffer{}
+	l := New(
+		WithOutput(buf),
+		WithFormat(FormatJSON),
+		WithContextExtractor(requestID.Extract, tenant, nil),
+		WithMaskKey("email", MaskEmail),
+	)
+
+	ctx := requestID.WithValue(context.Background(), "req_777")
+	ctx = ContextWith(ctx, slog.String("email", "antonioh@gmail.com"))
+	ctx = ContextWith(ctx, slog.Int("attempt", 2))
+	l.InfoContext(ctx, "handled")
+
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	assert.Equal(t, "req_777", m["request_id"])
+	assert.Equal(t, "acme", m["tenant"])
+	assert.Equal(t, "an***h@gmail.com", m["email"], "stashed attributes are masked")
+	assert.Equal(t, float64(2), m["attempt"])
+
+	buf.Reset()
+	l.Info("no context")
+	assert.NotContains(t, buf.String(), "request_id")
+	assert.Contains(t, buf.String(), `"tenant":"acme"`)
+
+	l.UpdateConfig(func(c *Config) { c.ContextExtractors = append(c.ContextExtractors, nil) })
+	buf.Reset()
+	assert.NotPanics(t, func() { l.InfoContext(ctx, "nil extractor") })
+	assert.Contains(t, buf.String(), `"request_id":"req_777"`)
+}
+
+func TestContextWith(t *testing.T) {
+	parent := ContextWith(context.Background(), slog.String("a", "1"))
+	child := ContextWith(parent, slog.String("b", "2"))
+	sibling := ContextWith(parent, slog.String("c", "3"))
+
+	assert.Equal(t, []slog.Attr{slog.String("a", "1")}, ContextAttrs(parent))
+	assert.Equal(t, []slog.Attr{slog.String("a", "1"), slog.String("b", "2")}, ContextAttrs(child))
+	assert.Equal(t, []slog.Attr{slog.String("a", "1"), slog.String("c", "3")}, ContextAttrs(sibling))
+	assert.Equal(t, parent, ContextWith(parent))
+}
+
+func TestAppendCtx(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(
+		WithOutput(buf),
+		WithFormat(FormatJSON),
+		WithMaskKey("card", MaskCard),
+		WithRemoval(NewRemovalSet().Add("token")),
+		WithContextExtractor(
+			func(ctx context.Context) []slog.Attr {
+				return []slog.Attr{slog.String("stage", "extractor")}
+			},
+		),
+	)
+
+	ctx := AppendCtx(context.Background(), "order_id", 7, "stage", "checkout")
+	ctx = AppendCtx(ctx, slog.String("card", "4276123456780000"), "token", "secret")
+	inner := AppendCtx(ctx, "order_id", 8, "dangling")
+
+	l.InfoContext(ctx, "outer")
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	assert.Equal(t, float64(7), m["order_id"])
+	assert.Equal(t, "checkout", m["stage"], "context attributes override extractors")
+	assert.Equal(t, "4276********0000", m["card"])
+	assert.NotContains(t, m, "token")
+
+	buf.Reset()
+	l.InfoContext(inner, "inner")
+	m = nil
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	assert.Equal(t, float64(8), m["order_id"], "the derived context replaces the value")
+	assert.Equal(t, "dangling", m["!BADKEY"])
+	assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte(`"order_id"`)))
+}
+
+func TestContextWithLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(WithOutput(buf), WithLevel(slog.LevelInfo))
+
+	debugCtx := ContextWithLevel(context.Background(), LevelTrace)
+	l.TraceContext(debugCtx, "traced request")
+	l.DebugContext(context.Background(), "other request")
+	assert.Contains(t, buf.String(), "traced request")
+	assert.NotContains(t, buf.String(), "other request")
+
+	buf.Reset()
+	quietCtx := ContextWithLevel(context.Background(), slog.LevelWarn)
+	l.InfoContext(quietCtx, "health check")
+	assert.Empty(t, buf.String(), "the override can also raise the level")
+
+	var lv slog.LevelVar
+	lv.Set(slog.LevelError)
+	dynCtx := ContextWithLevel(context.Background(), &lv)
+	l.WarnContext(dynCtx, "dropped")
+	lv.Set(slog.LevelDebug)
+	l.DebugContext(dynCtx, "kept")
+	assert.NotContains(t, buf.String(), "dropped")
+	assert.Contains(t, buf.String(), "kept")
+
+	lvl, ok := LevelFromContext(debugCtx)
+	assert.True(t, ok)
+	assert.Equal(t, LevelTrace, lvl)
+	_, ok = LevelFromContext(context.Background())
+	assert.False(t, ok)
+}
+
+func TestContextWithLevel_Sinks(t *testing.T) {
+	all, errs := &bytes.Buffer{}, &bytes.Buffer{}
+	l := New(
+		WithLevel(slog.LevelInfo),
+		WithSink(Sink{Name: "all", Output: all}),
+		WithSink(Sink{Name: "errors", Output: errs, Level: slog.LevelError}),
+	)
+
+	l.DebugContext(ContextWithLevel(context.Background(), slog.LevelDebug), "debugging")
+	assert.Contains(t, all.String(), "debugging")
+	assert.Empty(t, errs.String(), "sink levels still apply")
+}
@@ -0,0 +1,168 @@
+package slogx
+
+import (
+	"context"
+	"hash/maphash"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// RepeatedKey is the attribute with the number of duplicates suppressed by Config.Dedup,
+// added to the follow-up record written when the dedup window closes.
+const RepeatedKey = "repeated"
+
+// Dedup suppresses duplicate records. Records are duplicates when they have the same
+// level, message, logger name and values of the attributes listed in Keys (attributes
+// of the log call or of Logger.With, matched by top-level key).
+//
+// The first record is written and opens a window; duplicates within it are dropped.
+// The window is sliding: it closes once no duplicate was logged for Window, but at
+// the latest MaxWindow after it opened, so that a steady stream of duplicates is
+// still reported. When the window closes (or on Logger.Flush), the last duplicate is
+// written once more with RepeatedKey set to the number of dropped records, and the
+// next duplicate opens a new window. The zero value disables deduplication.
+type Dedup struct {
+	Window time.Duration
+	// MaxWindow caps how long a window stays open; zero means 10 times Window.
+	MaxWindow time.Duration
+	Keys      []string
+}
+
+func (d Dedup) enabled() bool {
+	return d.Window > 0
+}
+
+// maxWindow returns MaxWindow, defaulting to 10 times Window.
+func (d Dedup) maxWindow() time.Duration {
+	if d.MaxWindow > 0 {
+		return d.MaxWindow
+	}
+	return 10 * d.Window
+}
+
+// dedupEntry is an open dedup window.
+type dedupEntry struct {
+	timer *time.Timer
+	// closeBy is when the window closes even if duplicates keep coming (Dedup.MaxWindow).
+	closeBy time.Time
+	// repeated is the number of dropped duplicates; last is the latest of them and
+	// h and ctx are what it was logged with, ctx without its cancellation.
+	repeated int
+	last     slog.Record
+	h        *DynamicHandler
+	ctx      context.Context
+}
+
+// dedup holds the open dedup windows of a logger and its derived loggers.
+type dedup struct {
+	mu      sync.Mutex
+	seed    maphash.Seed
+	entries map[uint64]*dedupEntry
+}
+
+func newDedup() *dedup {
+	return &dedup{seed: maphash.MakeSeed()}
+}
+
+// suppress reports whether r, logged through h, is a duplicate within an open window.
+// Otherwise it opens a window for r, if cfg.Dedup is enabled.
+func (d *dedup) suppress(ctx context.Context, h *DynamicHandler, cfg *Config, r slog.Record) bool {
+	if d == nil || !cfg.Dedup.enabled() {
+		return false
+	}
+	key := d.hash(h, cfg.Dedup.Keys, r)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if e, ok := d.entries[key]; ok {
+		e.repeated++
+		e.last, e.h, e.ctx = r.Clone(), h, context.WithoutCancel(ctx)
+		// Slide the window. If the timer already fired, close is waiting for d.mu and
+		// the extra call it schedules finds the window gone.
+		e.timer.Reset(min(cfg.Dedup.Window, time.Until(e.closeBy)))
+		return true
+	}
+	if len(d.entries) >= maxThrottleKeys {
+		return false
+	}
+
+	if d.entries == nil {
+		d.entries = make(map[uint64]*dedupEntry)
+	}
+	e := &dedupEntry{closeBy: time.Now().Add(cfg.Dedup.maxWindow())}
+	e.timer = time.AfterFunc(cfg.Dedup.Window, func() { d.close(key, e) })
+	d.entries[key] = e
+	return false
+}
+
+// hash identifies the duplicates of r: level, message, logger name and selected attributes.
+func (d *dedup) hash(h *DynamicHandler, keys []string, r slog.Record) uint64 {
+	var mh maphash.Hash
+	mh.SetSeed(d.seed)
+	_, _ = mh.WriteString(r.Level.String())
+	_ = mh.WriteByte(0)
+	_, _ = mh.WriteString(r.Message)
+	_ = mh.WriteByte(0)
+	_, _ = mh.WriteString(h.name)
+
+	add := func(a slog.Attr) bool {
+		for _, k := range keys {
+			if a.Key == k {
+				_ = mh.WriteByte(0)
+				_, _ = mh.WriteString(a.Key)
+				_ = mh.WriteByte('=')
+				_, _ = mh.WriteString(a.Value.Resolve().String())
+			}
+		}
+		return true
+	}
+	if len(keys) > 0 {
+		for _, a := range h.attrs {
+			add(a)
+		}
+		r.Attrs(add)
+	}
+	return mh.Sum64()
+}
+
+// close ends the window of e and writes its follow-up record, if anything was dropped.
+func (d *dedup) close(key uint64, e *dedupEntry) {
+	d.mu.Lock()
+	if d.entries[key] != e {
+		d.mu.Unlock()
+		return
+	}
+	delete(d.entries, key)
+	d.mu.Unlock()
+
+	e.followUp()
+}
+
+// flush closes every open window.
+func (d *dedup) flush() {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	entries := d.entries
+	d.entries = nil
+	d.mu.Unlock()
+
+	for _, e := range entries {
+		e.timer.Stop()
+		e.followUp()
+	}
+}
+
+// followUp writes the last dropped duplicate with the number of dropped records.
+func (e *dedupEntry) followUp() {
+	if e.repeated == 0 {
+		return
+	}
+	r := e.last.Clone()
+	r.AddAttrs(slog.Int(RepeatedKey, e.repeated))
+	_ = e.h.handle(e.ctx, r)
+}
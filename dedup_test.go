@@ -0,0 +1,115 @@
+package slogx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedup_Flush(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithFormat(FormatJSON), WithDedup(time.Hour, "host"))
+	db := l.Named("db").With("host", "db1")
+
+	for i := 0; i < 5; i++ {
+		db.Error("connect failed", "error", Err(errors.New("refused")), "attempt", i)
+	}
+	db.Warn("connect failed")                     // another level
+	l.Error("connect failed", "host", "db1")      // another logger name
+	db.Error("connect failed", "host", "replica") // another value of a selected key
+	require.Len(t, jsonLines(t, buf.String()), 4)
+
+	require.NoError(t, l.Flush(context.Background()))
+	lines := jsonLines(t, buf.String())
+	require.Len(t, lines, 5)
+	last := lines[4]
+	assert.Equal(t, "connect failed", last["msg"])
+	assert.Equal(t, "ERROR", last["level"])
+	assert.Equal(t, "db", last[LoggerKey])
+	assert.Equal(t, float64(4), last[RepeatedKey])
+	assert.Equal(t, float64(4), last["attempt"], "the follow-up is the last duplicate")
+
+	// The window was closed by Flush: the next record is written again
+	db.Error("connect failed")
+	assert.Len(t, jsonLines(t, buf.String()), 6)
+	require.NoError(t, l.Flush(context.Background()))
+	assert.Len(t, jsonLines(t, buf.String()), 6, "no follow-up without duplicates")
+}
+
+func TestDedup_Window(t *testing.T) {
+	out := &syncBuffer{}
+	l := New(WithOutput(out), WithDedup(30*time.Millisecond))
+
+	for i := 0; i < 10; i++ {
+		l.Warn("retrying")
+	}
+	assert.Eventually(
+		t, func() bool {
+			return strings.Contains(out.String(), "repeated=9")
+		}, time.Second, 5*time.Millisecond,
+	)
+	assert.Equal(t, 2, strings.Count(out.String(), "msg=retrying"))
+
+	// Disabling takes effect for new records
+	l.UpdateConfig(func(c *Config) { c.Dedup = Dedup{} })
+	l.Warn("retrying")
+	l.Warn("retrying")
+	assert.Equal(t, 4, strings.Count(out.String(), "msg=retrying"))
+}
+
+func TestDedup_SlidingWindow(t *testing.T) {
+	out := &syncBuffer{}
+	l := New(WithOutput(out), WithFormat(FormatJSON))
+	l.UpdateConfig(func(c *Config) { c.Dedup = Dedup{Window: 40 * time.Millisecond, MaxWindow: time.Hour} })
+
+	// Every duplicate keeps the window open
+	for i := 0; i < 10; i++ {
+		l.Warn("retrying")
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Len(t, jsonLines(t, out.String()), 1)
+	require.Eventually(
+		t, func() bool { return len(jsonLines(t, out.String())) == 2 }, time.Second, 5*time.Millisecond,
+	)
+	assert.Equal(t, float64(9), jsonLines(t, out.String())[1][RepeatedKey])
+
+	// MaxWindow closes the window even if duplicates keep coming
+	l.UpdateConfig(func(c *Config) { c.Dedup.MaxWindow = 60 * time.Millisecond })
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(out.String(), `"msg":"stuck","repeated"`) && time.Now().Before(deadline) {
+		l.Warn("stuck")
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.Contains(t, out.String(), `"msg":"stuck","repeated"`)
+}
+
+func TestDedup_CancelledContext(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(
+		WithOutput(&buf), WithDedup(time.Hour),
+		WithContextExtractor(
+			func(ctx context.Context) []slog.Attr {
+				if ctx.Err() != nil {
+					return []slog.Attr{slog.Bool("cancelled", true)}
+				}
+				return nil
+			},
+		),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l.InfoContext(ctx, "request failed")
+	l.InfoContext(ctx, "request failed")
+	cancel()
+
+	require.NoError(t, l.Flush(context.Background()))
+	assert.Contains(t, buf.String(), "repeated=1")
+	assert.NotContains(t, buf.String(), "cancelled", "the follow-up outlives the request")
+}
@@ -0,0 +1,204 @@
+package slogx
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// entry is a fully prepared record passed to an encodeFunc: built-in attributes
+// and user attributes have already gone through ReplaceAttr and are resolved.
+// A built-in attribute with an empty key was removed by ReplaceAttr.
+type entry struct {
+	// level is the original record level, independent of how the level attribute is rendered.
+	level slog.Level
+	time  slog.Attr
+	lvl   slog.Attr
+	msg   slog.Attr
+	// attrs holds the user attributes; groups are nested slog.KindGroup values.
+	attrs []slog.Attr
+}
+
+// encodeFunc appends the encoded form of e (including a trailing newline) to buf.
+type encodeFunc func(buf []byte, e *entry) []byte
+
+// openGroup is a group opened with WithGroup together with the attributes added inside it.
+type openGroup struct {
+	name  string
+	attrs []slog.Attr
+}
+
+// encodeHandler is the base slog.Handler for the formats implemented by slogx
+// itself. It mirrors the semantics of the stdlib handlers (ReplaceAttr is called
+// for every non-group attribute with its group path, empty groups are omitted,
+// groups with an empty key are inlined) and leaves only the encoding to encode.
+type encodeHandler struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	opts   slog.HandlerOptions
+	encode encodeFunc
+
+	attrs  []slog.Attr
+	groups []openGroup
+}
+
+func newEncodeHandler(w io.Writer, opts *slog.HandlerOptions, encode encodeFunc) *encodeHandler {
+	h := &encodeHandler{w: w, mu: &sync.Mutex{}, encode: encode}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+// Enabled reports whether the level passes HandlerOptions.Level.
+func (h *encodeHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+// Handle prepares the entry, encodes it and writes it with a single Write call.
+func (h *encodeHandler) Handle(_ context.Context, r slog.Record) error {
+	e := &entry{level: r.Level}
+
+	if !r.Time.IsZero() {
+		e.time = h.replaceBuiltin(slog.Time(slog.TimeKey, r.Time.Round(0)))
+	}
+	e.lvl = h.replaceBuiltin(slog.Any(slog.LevelKey, r.Level))
+	e.msg = h.replaceBuiltin(slog.String(slog.MessageKey, r.Message))
+
+	groupNames := make([]string, len(h.groups))
+	for i, g := range h.groups {
+		groupNames[i] = g.name
+	}
+
+	var recordAttrs []slog.Attr
+	r.Attrs(
+		func(a slog.Attr) bool {
+			recordAttrs = h.appendAttr(recordAttrs, groupNames, a)
+			return true
+		},
+	)
+
+	// Fold the open groups from the innermost one outwards; empty groups disappear.
+	inner := recordAttrs
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		attrs := make([]slog.Attr, 0, len(h.groups[i].attrs)+len(inner))
+		attrs = append(attrs, h.groups[i].attrs...)
+		attrs = append(attrs, inner...)
+		if len(attrs) == 0 {
+			inner = nil
+			continue
+		}
+		inner = []slog.Attr{{Key: h.groups[i].name, Value: slog.GroupValue(attrs...)}}
+	}
+	e.attrs = make([]slog.Attr, 0, len(h.attrs)+len(inner))
+	e.attrs = append(e.attrs, h.attrs...)
+	e.attrs = append(e.attrs, inner...)
+
+	buf := h.encode(make([]byte, 0, 256), e)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf)
+	return err
+}
+
+// WithAttrs returns a handler with the attributes added to the innermost open group.
+func (h *encodeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	groupNames := make([]string, len(h.groups))
+	for i, g := range h.groups {
+		groupNames[i] = g.name
+	}
+
+	var replaced []slog.Attr
+	for _, a := range attrs {
+		replaced = h.appendAttr(replaced, groupNames, a)
+	}
+
+	h2 := h.clone()
+	if len(h2.groups) == 0 {
+		h2.attrs = append(h2.attrs, replaced...)
+	} else {
+		last := &h2.groups[len(h2.groups)-1]
+		last.attrs = append(append([]slog.Attr(nil), last.attrs...), replaced...)
+	}
+	return h2
+}
+
+// WithGroup returns a handler that nests all further attributes in the group.
+func (h *encodeHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := h.clone()
+	h2.groups = append(h2.groups, openGroup{name: name})
+	return h2
+}
+
+func (h *encodeHandler) clone() *encodeHandler {
+	h2 := *h
+	h2.attrs = append([]slog.Attr(nil), h.attrs...)
+	h2.groups = append([]openGroup(nil), h.groups...)
+	return &h2
+}
+
+// replaceBuiltin applies ReplaceAttr to a built-in attribute (time, level, msg).
+func (h *encodeHandler) replaceBuiltin(a slog.Attr) slog.Attr {
+	if h.opts.ReplaceAttr == nil {
+		return a
+	}
+	a = h.opts.ReplaceAttr(nil, a)
+	a.Value = a.Value.Resolve()
+	return a
+}
+
+// appendAttr resolves a, applies ReplaceAttr and appends the result to dst.
+func (h *encodeHandler) appendAttr(dst []slog.Attr, groups []string, a slog.Attr) []slog.Attr {
+	a.Value = a.Value.Resolve()
+
+	if a.Value.Kind() != slog.KindGroup && h.opts.ReplaceAttr != nil {
+		a = h.opts.ReplaceAttr(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		members := a.Value.Group()
+		subGroups := groups
+		if a.Key != "" {
+			subGroups = append(groups[:len(groups):len(groups)], a.Key)
+		}
+		var sub []slog.Attr
+		for _, m := range members {
+			sub = h.appendAttr(sub, subGroups, m)
+		}
+		if len(sub) == 0 {
+			return dst
+		}
+		if a.Key == "" {
+			return append(dst, sub...)
+		}
+		return append(dst, slog.Attr{Key: a.Key, Value: slog.GroupValue(sub...)})
+	}
+
+	if a.Key == "" {
+		return dst
+	}
+	return append(dst, a)
+}
+
+// timeValue returns the time stored in a, if it still holds one after ReplaceAttr.
+func timeValue(a slog.Attr) (time.Time, bool) {
+	if a.Value.Kind() == slog.KindTime {
+		return a.Value.Time(), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,364 @@
+package slogx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConfigListener is notified with the previous and the new configuration every time
+// a configuration is published. Both configurations must be treated as read-only.
+type ConfigListener func(oldCfg, newCfg *Config)
+
+// listener is a subscribed ConfigListener; the pointer identifies the subscription.
+type listener struct {
+	fn ConfigListener
+}
+
+// Subscribe registers fn to be notified of every configuration update, including the
+// start and end of temporary overrides. Listeners are called in the order of the
+// versions and of subscription, one update at a time and without holding any lock:
+// the goroutine that publishes an update delivers it, unless another one is already
+// delivering earlier updates, in which case that one delivers it too (so the update
+// may return before its listeners have run). A listener may update the configuration;
+// it is notified of that update after it returns. A panic in a listener reaches the
+// caller of the update being delivered, which is not delivered further; later updates are.
+// The returned function cancels the subscription.
+func (l *Logger) Subscribe(fn ConfigListener) (unsubscribe func()) {
+	st := l.state
+	sub := &listener{fn: fn}
+
+	st.listenersMu.Lock()
+	st.listeners = append(st.listeners, sub)
+	st.listenersMu.Unlock()
+
+	return func() {
+		st.listenersMu.Lock()
+		defer st.listenersMu.Unlock()
+		for i, s := range st.listeners {
+			if s == sub {
+				st.listeners = append(st.listeners[:i:i], st.listeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// notification is a published configuration waiting to be delivered to the listeners.
+type notification struct {
+	oldCfg, newCfg *Config
+}
+
+// notifier queues the notifications of published configurations so that they are
+// delivered in version order and outside of state.cfgMu.
+type notifier struct {
+	mu sync.Mutex
+	// pending holds the queued notifications by the version of the new configuration.
+	pending map[uint64]notification
+	// next is the version of the next notification to deliver.
+	next uint64
+	// delivering is set while a goroutine is delivering notifications.
+	delivering bool
+}
+
+// publish replaces cur with cfg, giving it the next Version, unless another configuration
+// was published after cur was loaded; it reports whether cfg was published. The
+// notification is queued: the caller must call deliver once it holds no lock.
+func (l *Logger) publish(cur, cfg *Config) bool {
+	cfg.Version = cur.Version + 1
+	if !l.cfgPtr.CompareAndSwap(cur, cfg) {
+		return false
+	}
+	if l.state == nil {
+		return true
+	}
+
+	n := &l.state.notify
+	n.mu.Lock()
+	if n.pending == nil {
+		n.pending = make(map[uint64]notification)
+	}
+	n.pending[cfg.Version] = notification{oldCfg: cur, newCfg: cfg}
+	n.mu.Unlock()
+	return true
+}
+
+// deliver notifies the listeners of the queued configurations in version order,
+// unless another goroutine is already doing so. Notifications are delivered without
+// holding any lock, so listeners may update the configuration themselves.
+func (l *Logger) deliver() {
+	if l.state == nil {
+		return
+	}
+	n := &l.state.notify
+	n.mu.Lock()
+	if n.delivering {
+		n.mu.Unlock()
+		return
+	}
+	n.delivering = true
+	n.mu.Unlock()
+
+	// A panicking listener must not stop the delivery of later updates; the update
+	// being delivered is dropped and the panic goes on to the caller
+	finished := false
+	defer func() {
+		if !finished {
+			n.mu.Lock()
+			n.delivering = false
+			n.mu.Unlock()
+		}
+	}()
+
+	for {
+		n.mu.Lock()
+		e, ok := n.pending[n.next]
+		if !ok {
+			// Cleared with the check, so that an update queued next is delivered by its caller
+			n.delivering = false
+			n.mu.Unlock()
+			finished = true
+			return
+		}
+		delete(n.pending, n.next)
+		n.next++
+		n.mu.Unlock()
+
+		l.notify(e.oldCfg, e.newCfg)
+	}
+}
+
+// notify releases the write locks of retired outputs, updates the flight recorder,
+// calls the listeners, and if the new configuration has LogChanges set and anything
+// changed, writes a "logger reconfigured" record.
+func (l *Logger) notify(oldCfg, newCfg *Config) {
+	// Outputs are compared with the latest configuration: a later one may use them again
+	l.state.releaseWriteLocks(l.cfgPtr.Load())
+	l.state.flightRecorderChanged(oldCfg.FlightRecorder, newCfg.FlightRecorder)
+
+	l.state.listenersMu.Lock()
+	listeners := l.state.listeners
+	l.state.listenersMu.Unlock()
+	for _, s := range listeners {
+		s.fn(oldCfg, newCfg)
+	}
+
+	if newCfg.LogChanges && l.state.root != nil {
+		if changes := DiffConfig(oldCfg, newCfg); len(changes) > 0 {
+			l.state.root.LogAttrs(
+				context.Background(), slog.LevelInfo, "logger reconfigured",
+				slog.Any("changes", changes),
+			)
+		}
+	}
+}
+
+// ConfigChange describes one difference between two configurations.
+// Old and New are empty when the entry was added or removed respectively.
+type ConfigChange struct {
+	// Field is the Config field, with the map key or sink name if any
+	// (e.g. "Level", "MaskKeys.email", "Sinks.audit").
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// String returns "Field: old -> new".
+func (c ConfigChange) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Field, orNone(c.Old), orNone(c.New))
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
+
+// DiffConfig describes what changed between oldCfg and newCfg, sorted by field.
+// Writers, maskers and extractors are compared by identity, and the HashKey
+// value is never included.
+func DiffConfig(oldCfg, newCfg *Config) []ConfigChange {
+	if oldCfg == nil {
+		oldCfg = &Config{}
+	}
+	if newCfg == nil {
+		newCfg = &Config{}
+	}
+
+	var changes []ConfigChange
+	add := func(field, o, n string) {
+		if o != n {
+			changes = append(changes, ConfigChange{Field: field, Old: o, New: n})
+		}
+	}
+
+	add("Level", getLevelName(oldCfg.Level, oldCfg.LevelNames), getLevelName(newCfg.Level, newCfg.LevelNames))
+	add("Format", oldCfg.Format.String(), newCfg.Format.String())
+	add("Output", identity(oldCfg.Output), identity(newCfg.Output))
+	add("Masker", identity(oldCfg.Masker), identity(newCfg.Masker))
+	add("TraceExtractor", identity(oldCfg.TraceExtractor), identity(newCfg.TraceExtractor))
+	add("Scan", scanKindString(oldCfg.Scan), scanKindString(newCfg.Scan))
+	add("HashKeyID", oldCfg.HashKeyID, newCfg.HashKeyID)
+	if string(oldCfg.HashKey) != string(newCfg.HashKey) {
+		changes = append(changes, ConfigChange{Field: "HashKey", Old: "<redacted>", New: "<redacted>"})
+	}
+	add("ContextKeys", strings.Join(oldCfg.ContextKeys, ","), strings.Join(newCfg.ContextKeys, ","))
+	add("ContextExtractors", fmt.Sprint(len(oldCfg.ContextExtractors)), fmt.Sprint(len(newCfg.ContextExtractors)))
+	add("ScanPatterns", scanPatternNames(oldCfg.ScanPatterns), scanPatternNames(newCfg.ScanPatterns))
+	add("Sampling", samplingString(oldCfg.Sampling), samplingString(newCfg.Sampling))
+	add("RateLimit", rateLimitString(oldCfg.RateLimit), rateLimitString(newCfg.RateLimit))
+	add("SuppressionSummary", durationString(oldCfg.SuppressionSummary), durationString(newCfg.SuppressionSummary))
+	add("Dedup", dedupString(oldCfg.Dedup), dedupString(newCfg.Dedup))
+	add("FlightRecorder", flightRecorderString(oldCfg.FlightRecorder), flightRecorderString(newCfg.FlightRecorder))
+
+	diffMap(&changes, "MaskKeys", oldCfg.MaskKeys, newCfg.MaskKeys, MaskType.String)
+	diffMap(&changes, "RemoveKeys", oldCfg.RemoveKeys, newCfg.RemoveKeys, func(struct{}) string { return "set" })
+	diffMap(
+		&changes, "LoggerLevels", oldCfg.LoggerLevels, newCfg.LoggerLevels, func(l slog.Level) string {
+			return getLevelName(l, newCfg.LevelNames)
+		},
+	)
+	diffMap(
+		&changes, "LevelNames", levelNameMap(oldCfg.LevelNames), levelNameMap(newCfg.LevelNames),
+		func(s string) string { return s },
+	)
+	diffMap(&changes, "Sinks", sinkMap(oldCfg.Sinks), sinkMap(newCfg.Sinks), sinkString)
+
+	sort.SliceStable(
+		changes, func(i, j int) bool {
+			return changes[i].Field < changes[j].Field
+		},
+	)
+	return changes
+}
+
+// diffMap appends a change for every key added, removed or changed between oldMap and newMap.
+func diffMap[V any](changes *[]ConfigChange, field string, oldMap, newMap map[string]V, str func(V) string) {
+	for k, ov := range oldMap {
+		o := str(ov)
+		n := ""
+		if nv, ok := newMap[k]; ok {
+			n = str(nv)
+		}
+		if o != n {
+			*changes = append(*changes, ConfigChange{Field: field + "." + k, Old: o, New: n})
+		}
+	}
+	for k, nv := range newMap {
+		if _, ok := oldMap[k]; !ok {
+			*changes = append(*changes, ConfigChange{Field: field + "." + k, New: str(nv)})
+		}
+	}
+}
+
+// identity describes a value compared by identity: its type and, for pointers, its address.
+func identity(v any) string {
+	if v == nil {
+		return ""
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Func, reflect.Map, reflect.Chan, reflect.Slice, reflect.UnsafePointer:
+		return fmt.Sprintf("%T(%#x)", v, rv.Pointer())
+	default:
+		return fmt.Sprintf("%T(%v)", v, v)
+	}
+}
+
+func scanKindString(k ScanKind) string {
+	if k == ScanNone {
+		return ""
+	}
+	return fmt.Sprintf("%#x", uint(k))
+}
+
+func scanPatternNames(patterns []ScanPattern) string {
+	names := make([]string, len(patterns))
+	for i, p := range patterns {
+		names[i] = p.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func samplingString(s Sampling) string {
+	if s == (Sampling{}) {
+		return ""
+	}
+	return fmt.Sprintf("interval=%s first=%d thereafter=%d", s.Interval, s.First, s.Thereafter)
+}
+
+func rateLimitString(rl RateLimit) string {
+	if rl.Rate == 0 && rl.Burst == 0 && rl.Key == nil {
+		return ""
+	}
+	key := "message"
+	if rl.Key != nil {
+		key = identity(rl.Key)
+	}
+	return fmt.Sprintf("rate=%g burst=%d key=%s", rl.Rate, rl.Burst, key)
+}
+
+func dedupString(d Dedup) string {
+	if d.Window == 0 && d.MaxWindow == 0 && len(d.Keys) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("window=%s max=%s keys=%s", d.Window, d.MaxWindow, strings.Join(d.Keys, ","))
+}
+
+func flightRecorderString(fr FlightRecorder) string {
+	if fr.Size == 0 && fr.Level == nil && fr.Trigger == nil {
+		return ""
+	}
+	level, trigger := "all", slog.LevelError.String()
+	if fr.Level != nil {
+		level = fr.Level.Level().String()
+	}
+	if fr.Trigger != nil {
+		trigger = fr.Trigger.Level().String()
+	}
+	return fmt.Sprintf("size=%d level=%s trigger=%s", fr.Size, level, trigger)
+}
+
+func durationString(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
+func levelNameMap(names LevelNames) map[string]string {
+	m := make(map[string]string, len(names))
+	for l, name := range names {
+		m[l.String()] = name
+	}
+	return m
+}
+
+func sinkMap(sinks []Sink) map[string]Sink {
+	m := make(map[string]Sink, len(sinks))
+	for i, s := range sinks {
+		name := s.Name
+		if name == "" {
+			name = fmt.Sprint(i)
+		}
+		m[name] = s
+	}
+	return m
+}
+
+func sinkString(s Sink) string {
+	level := ""
+	if s.Level != nil {
+		level = s.Level.Level().String()
+	}
+	return fmt.Sprintf(
+		"output=%s format=%s level=%s mask=%d remove=%d",
+		identity(s.Output), s.Format, level, len(s.MaskKeys), len(s.RemoveKeys),
+	)
+}
@@ -0,0 +1,152 @@
+package slogx
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_Subscribe(t *testing.T) {
+	l := New(WithOutput(&bytes.Buffer{}), WithLevel(slog.LevelInfo))
+
+	type update struct{ old, new slog.Level }
+	var got []update
+	unsubscribe := l.Subscribe(
+		func(oldCfg, newCfg *Config) {
+			got = append(got, update{oldCfg.Level, newCfg.Level})
+		},
+	)
+
+	l.SetLevel(slog.LevelDebug)
+	o := l.SetLevelFor(LevelTrace, time.Hour)
+	o.Cancel()
+	unsubscribe()
+	l.SetLevel(slog.LevelWarn)
+
+	assert.Equal(
+		t, []update{
+			{slog.LevelInfo, slog.LevelDebug},
+			{slog.LevelDebug, LevelTrace},
+			{LevelTrace, slog.LevelDebug},
+		}, got,
+	)
+}
+
+func TestLogger_SubscribeUpdates(t *testing.T) {
+	l := New(WithOutput(&bytes.Buffer{}), WithLevel(slog.LevelInfo))
+
+	var versions []uint64
+	l.Subscribe(
+		func(oldCfg, newCfg *Config) {
+			versions = append(versions, newCfg.Version)
+			// A listener may update the configuration, even during an override
+			if newCfg.Level == slog.LevelDebug {
+				l.SetLevel(slog.LevelWarn)
+			}
+		},
+	)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		o := l.SetLevelFor(LevelTrace, time.Hour)
+		l.SetLevel(slog.LevelDebug)
+		o.Cancel()
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("a listener updating the configuration deadlocked")
+	}
+
+	assert.Equal(t, slog.LevelWarn, l.cfgPtr.Load().Level)
+	start := versions[0]
+	assert.Equal(t, []uint64{start, start + 1, start + 2, start + 3}, versions, "notified in version order")
+}
+
+func TestLogger_SubscribePanic(t *testing.T) {
+	l := New(WithOutput(&bytes.Buffer{}), WithLevel(slog.LevelInfo))
+
+	var levels []slog.Level
+	l.Subscribe(
+		func(_, newCfg *Config) {
+			if newCfg.Level == slog.LevelDebug {
+				panic("listener failed")
+			}
+		},
+	)
+	l.Subscribe(func(_, newCfg *Config) { levels = append(levels, newCfg.Level) })
+
+	assert.PanicsWithValue(t, "listener failed", func() { l.SetLevel(slog.LevelDebug) })
+	assert.Equal(t, slog.LevelDebug, l.cfgPtr.Load().Level, "the update was published")
+
+	l.SetLevel(slog.LevelWarn)
+	l.SetLevel(slog.LevelError)
+	assert.Equal(t, []slog.Level{slog.LevelWarn, slog.LevelError}, levels, "later updates are still delivered")
+	assert.Empty(t, l.state.notify.pending)
+}
+
+func TestDiffConfig(t *testing.T) {
+	old := defaultOptions().initialConfig
+	old.MaskKeys["email"] = MaskEmail
+	old.RemoveKeys["token"] = struct{}{}
+	old.HashKey = []byte("k1")
+
+	next := old.Clone()
+	next.Level = slog.LevelWarn
+	next.Format = FormatJSON
+	next.MaskKeys["email"] = MaskHash
+	next.MaskKeys["card"] = MaskCard
+	delete(next.RemoveKeys, "token")
+	next.LoggerLevels["db"] = slog.LevelDebug
+	next.HashKey = []byte("k2")
+	next.Sinks = []Sink{{Name: "audit", Output: &bytes.Buffer{}}}
+	next.Sampling = Sampling{Interval: time.Second, First: 100}
+
+	changes := DiffConfig(old, next)
+	fields := make([]string, len(changes))
+	for i, c := range changes {
+		fields[i] = c.Field
+	}
+	assert.Equal(
+		t, []string{
+			"Format", "HashKey", "Level", "LoggerLevels.db", "MaskKeys.card", "MaskKeys.email",
+			"RemoveKeys.token", "Sampling", "Sinks.audit",
+		}, fields,
+	)
+
+	byField := make(map[string]ConfigChange)
+	for _, c := range changes {
+		byField[c.Field] = c
+	}
+	assert.Equal(t, "Level: TRACE -> WARN", byField["Level"].String())
+	assert.Equal(t, "MaskKeys.email: email -> hash", byField["MaskKeys.email"].String())
+	assert.Equal(t, "MaskKeys.card: <none> -> card", byField["MaskKeys.card"].String())
+	assert.Equal(t, "RemoveKeys.token: set -> <none>", byField["RemoveKeys.token"].String())
+	assert.NotContains(t, byField["HashKey"].String(), "k1")
+	assert.Equal(t, "Sampling: <none> -> interval=1s first=100 thereafter=0", byField["Sampling"].String())
+
+	assert.Empty(t, DiffConfig(old, old.Clone()))
+}
+
+func TestLogger_ChangeLog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(WithOutput(buf), WithFormat(FormatJSON), WithLevel(slog.LevelInfo), WithChangeLog())
+
+	l.WithGroup("req").UpdateConfig(func(c *Config) { c.MaskKeys["email"] = MaskEmail })
+
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	assert.Equal(t, "logger reconfigured", m["msg"])
+	assert.NotContains(t, m, "req", "the record is written by the root logger")
+	assert.Equal(t, []any{map[string]any{"field": "MaskKeys.email", "new": "email"}}, m["changes"])
+
+	buf.Reset()
+	l.UpdateConfig(func(c *Config) {})
+	assert.Empty(t, buf.String(), "updates without changes are not logged")
+}
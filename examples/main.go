@@ -8,6 +8,12 @@ import (
 	"github.com/salivare-io/slogx"
 )
 
+// Typed context keys cannot collide with keys of other packages
+var (
+	traceID   = slogx.NewContextKey[string]("trace_id")
+	requestID = slogx.NewContextKey[string]("request_id")
+)
+
 func main() {
 	// Initialization using Builder-options and Variadic functions
 	log := slogx.New(
@@ -31,7 +37,7 @@ func main() {
 		),
 
 		// Auto-context extraction
-		slogx.WithContextKeys("trace_id", "request_id"),
+		slogx.WithContextExtractor(traceID.Extract, requestID.Extract),
 	)
 
 	// Set as global (optional, but useful for libraries)
@@ -39,8 +45,8 @@ func main() {
 
 	// Working with context
 	ctx := context.Background()
-	ctx = context.WithValue(ctx, "trace_id", "tid_999")
-	ctx = context.WithValue(ctx, "request_id", "req_777")
+	ctx = traceID.WithValue(ctx, "tid_999")
+	ctx = requestID.WithValue(ctx, "req_777")
 
 	fmt.Println("Текстовый формат и маскирование (Builder)")
 	log.InfoContext(
@@ -0,0 +1,405 @@
+package slogx
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// backupTimeFormat is the timestamp embedded in rotated file names (app-20261016T120000.000.log).
+const backupTimeFormat = "20060102T150405.000"
+
+// compressSuffix is appended to rotated files compressed with gzip.
+const compressSuffix = ".gz"
+
+// fileOptions holds RotatingFile settings.
+type fileOptions struct {
+	maxSize    int64
+	interval   time.Duration
+	maxBackups int
+	maxAge     time.Duration
+	compress   bool
+	signals    []os.Signal
+	perm       os.FileMode
+}
+
+// FileOption is a functional configuration parameter for NewRotatingFile.
+type FileOption func(*fileOptions)
+
+// WithMaxSize rotates the file before a write would make it larger than n bytes.
+func WithMaxSize(n int64) FileOption {
+	return func(o *fileOptions) {
+		o.maxSize = n
+	}
+}
+
+// WithRotateEvery rotates the file on wall-clock boundaries of d (e.g. every hour on the hour).
+func WithRotateEvery(d time.Duration) FileOption {
+	return func(o *fileOptions) {
+		o.interval = d
+	}
+}
+
+// WithMaxBackups keeps at most n rotated files; older ones are deleted. Zero keeps all.
+func WithMaxBackups(n int) FileOption {
+	return func(o *fileOptions) {
+		o.maxBackups = n
+	}
+}
+
+// WithMaxAge deletes rotated files older than d. Zero keeps them regardless of age.
+func WithMaxAge(d time.Duration) FileOption {
+	return func(o *fileOptions) {
+		o.maxAge = d
+	}
+}
+
+// WithCompress gzip-compresses rotated files in the background.
+func WithCompress(enabled bool) FileOption {
+	return func(o *fileOptions) {
+		o.compress = enabled
+	}
+}
+
+// WithReopenSignal reopens the file whenever one of the signals is received, which is
+// what external tools such as logrotate expect after moving the file away.
+// Without arguments it listens for SIGHUP.
+func WithReopenSignal(sigs ...os.Signal) FileOption {
+	return func(o *fileOptions) {
+		if len(sigs) == 0 {
+			sigs = []os.Signal{syscall.SIGHUP}
+		}
+		o.signals = sigs
+	}
+}
+
+// WithFileMode sets the permissions used when creating log files (default 0644).
+func WithFileMode(perm os.FileMode) FileOption {
+	return func(o *fileOptions) {
+		o.perm = perm
+	}
+}
+
+// RotatingFile is a file output for Config.Output or Sink.Output that rotates by size
+// and/or wall-clock interval, keeps a bounded number of backups and can compress them.
+//
+// RotatingFile is managed by slogx: Logger.Close and Logger.SwapOutput close it.
+type RotatingFile struct {
+	path string
+	opts fileOptions
+
+	mu           sync.Mutex
+	file         *os.File
+	size         int64
+	nextRotation time.Time
+	closed       bool
+
+	sigCh chan os.Signal
+	done  chan struct{}
+	// background tracks compression and cleanup goroutines; bgMu runs them one at a time
+	// so cleanup never sees a backup that is still being compressed.
+	background sync.WaitGroup
+	bgMu       sync.Mutex
+}
+
+// NewRotatingFile opens (or creates) the file at path and applies the options.
+func NewRotatingFile(path string, opts ...FileOption) (*RotatingFile, error) {
+	o := fileOptions{perm: 0o644}
+	for _, fn := range opts {
+		if fn != nil {
+			fn(&o)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("slogx: create log directory: %w", err)
+	}
+
+	r := &RotatingFile{path: path, opts: o, done: make(chan struct{})}
+	if err := r.open(time.Now()); err != nil {
+		return nil, err
+	}
+
+	if len(o.signals) > 0 {
+		r.sigCh = make(chan os.Signal, 1)
+		signal.Notify(r.sigCh, o.signals...)
+		go r.watchSignals()
+	}
+
+	return r, nil
+}
+
+// Write appends p to the current file, rotating it first if needed.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return 0, ErrWriterClosed
+	}
+
+	now := time.Now()
+	if r.shouldRotate(now, int64(len(p))) {
+		// A failed rotation keeps writing into the current file rather than losing the record.
+		if err := r.rotate(now); err != nil && r.file == nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Rotate forces a rotation of the current file.
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrWriterClosed
+	}
+	return r.rotate(time.Now())
+}
+
+// Reopen closes and reopens the file at the configured path without renaming it.
+// Use it (or WithReopenSignal) after an external tool moved the file away. If the
+// file cannot be opened, writing goes on into the current one.
+func (r *RotatingFile) Reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrWriterClosed
+	}
+	prev := r.file
+	if err := r.open(time.Now()); err != nil {
+		return err
+	}
+	_ = prev.Close()
+	return nil
+}
+
+// Flush commits the current file contents to stable storage.
+func (r *RotatingFile) Flush(context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	return r.file.Sync()
+}
+
+// Close closes the file, stops the signal watcher and waits for background compression.
+// It is safe to call Close more than once.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	err := r.file.Close()
+	r.mu.Unlock()
+
+	if r.sigCh != nil {
+		signal.Stop(r.sigCh)
+		close(r.done)
+	}
+	r.background.Wait()
+	return err
+}
+
+func (r *RotatingFile) watchSignals() {
+	for {
+		select {
+		case <-r.sigCh:
+			_ = r.Reopen()
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// open opens the file at r.path in append mode. r.mu must be held.
+func (r *RotatingFile) open(now time.Time) error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, r.opts.perm)
+	if err != nil {
+		return fmt.Errorf("slogx: open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("slogx: stat log file: %w", err)
+	}
+
+	r.file = f
+	r.size = info.Size()
+	if r.opts.interval > 0 {
+		r.nextRotation = now.Truncate(r.opts.interval).Add(r.opts.interval)
+	}
+	return nil
+}
+
+// shouldRotate reports whether writing n more bytes at now requires a rotation. r.mu must be held.
+func (r *RotatingFile) shouldRotate(now time.Time, n int64) bool {
+	if r.opts.maxSize > 0 && r.size > 0 && r.size+n > r.opts.maxSize {
+		return true
+	}
+	return r.opts.interval > 0 && !now.Before(r.nextRotation)
+}
+
+// rotate renames the current file to a timestamped backup and opens a fresh one.
+// Compression and cleanup of old backups run in the background. r.mu must be held.
+func (r *RotatingFile) rotate(now time.Time) error {
+	_ = r.file.Close()
+	r.file = nil
+
+	backup := r.backupName(now)
+	if err := os.Rename(r.path, backup); err != nil && !os.IsNotExist(err) {
+		if openErr := r.open(now); openErr != nil {
+			return openErr
+		}
+		return fmt.Errorf("slogx: rotate log file: %w", err)
+	}
+	if err := r.open(now); err != nil {
+		return err
+	}
+
+	r.background.Add(1)
+	go func() {
+		defer r.background.Done()
+		r.bgMu.Lock()
+		defer r.bgMu.Unlock()
+		if r.opts.compress {
+			_ = compressFile(backup)
+		}
+		r.cleanup(now)
+	}()
+	return nil
+}
+
+// backupName returns a free file name for a backup rotated at t.
+func (r *RotatingFile) backupName(t time.Time) string {
+	dir, prefix, ext := r.nameParts()
+	base := filepath.Join(dir, prefix+t.Format(backupTimeFormat))
+	name := base + ext
+	for i := 1; fileExists(name) || fileExists(name+compressSuffix); i++ {
+		name = fmt.Sprintf("%s.%d%s", base, i, ext)
+	}
+	return name
+}
+
+// nameParts splits "dir/app.log" into "dir", "app-" and ".log".
+func (r *RotatingFile) nameParts() (dir, prefix, ext string) {
+	dir = filepath.Dir(r.path)
+	name := filepath.Base(r.path)
+	ext = filepath.Ext(name)
+	return dir, strings.TrimSuffix(name, ext) + "-", ext
+}
+
+// cleanup removes backups beyond MaxBackups or older than MaxAge.
+func (r *RotatingFile) cleanup(now time.Time) {
+	if r.opts.maxBackups <= 0 && r.opts.maxAge <= 0 {
+		return
+	}
+
+	dir, prefix, ext := r.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	type backup struct {
+		path string
+		at   time.Time
+		seq  int
+	}
+	var backups []backup
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimPrefix(name, prefix)
+		stamp = strings.TrimSuffix(stamp, compressSuffix)
+		if !strings.HasSuffix(stamp, ext) {
+			continue
+		}
+		stamp = strings.TrimSuffix(stamp, ext)
+		if len(stamp) < len(backupTimeFormat) {
+			continue
+		}
+		// Collision suffixes (".1", ".2", ...) follow the timestamp and are ignored.
+		at, err := time.ParseInLocation(backupTimeFormat, stamp[:len(backupTimeFormat)], time.Local)
+		if err != nil {
+			continue
+		}
+		seq, _ := strconv.Atoi(strings.TrimPrefix(stamp[len(backupTimeFormat):], "."))
+		backups = append(backups, backup{path: filepath.Join(dir, name), at: at, seq: seq})
+	}
+
+	// Newest first; backups rotated within the same millisecond are ordered by their suffix.
+	sort.Slice(
+		backups, func(i, j int) bool {
+			if !backups[i].at.Equal(backups[j].at) {
+				return backups[i].at.After(backups[j].at)
+			}
+			return backups[i].seq > backups[j].seq
+		},
+	)
+
+	for i, b := range backups {
+		expired := r.opts.maxAge > 0 && now.Sub(b.at) > r.opts.maxAge
+		excess := r.opts.maxBackups > 0 && i >= r.opts.maxBackups
+		if expired || excess {
+			_ = os.Remove(b.path)
+		}
+	}
+}
+
+// compressFile gzips path into path+".gz" and removes the original.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(dst.Name())
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(dst.Name())
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
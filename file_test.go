@@ -0,0 +1,147 @@
+package slogx
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile_SizeRotationAndBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	rf, err := NewRotatingFile(path, WithMaxSize(64), WithMaxBackups(2), WithCompress(true))
+	require.NoError(t, err)
+
+	line := strings.Repeat("x", 40) + "\n"
+	for i := 0; i < 5; i++ {
+		_, err := rf.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, rf.Close())
+
+	matches, err := filepath.Glob(filepath.Join(dir, "app-*.log.gz"))
+	require.NoError(t, err)
+	assert.Len(t, matches, 2)
+
+	f, err := os.Open(matches[0])
+	require.NoError(t, err)
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	data, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, line, string(data))
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, line, string(current))
+}
+
+func TestRotatingFile_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	rf, err := NewRotatingFile(path)
+	require.NoError(t, err)
+	defer rf.Close()
+
+	_, _ = rf.Write([]byte("before\n"))
+	require.NoError(t, os.Rename(path, path+".1"))
+	require.NoError(t, rf.Reopen())
+	_, _ = rf.Write([]byte("after\n"))
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "after\n", string(current))
+
+	// A failed reopen keeps writing into the current file
+	require.NoError(t, os.Rename(path, path+".2"))
+	require.NoError(t, os.Mkdir(path, 0o755))
+	assert.Error(t, rf.Reopen())
+	_, err = rf.Write([]byte("kept\n"))
+	require.NoError(t, err)
+
+	kept, err := os.ReadFile(path + ".2")
+	require.NoError(t, err)
+	assert.Equal(t, "after\nkept\n", string(kept))
+}
+
+func TestLogger_SwapOutput(t *testing.T) {
+	dir := t.TempDir()
+	first, err := NewRotatingFile(filepath.Join(dir, "first.log"))
+	require.NoError(t, err)
+	second, err := NewRotatingFile(filepath.Join(dir, "second.log"))
+	require.NoError(t, err)
+
+	l := New(WithOutput(first))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				l.Info("record")
+			}
+		}()
+	}
+
+	require.NoError(t, l.SwapOutput(context.Background(), second))
+	wg.Wait()
+	require.NoError(t, l.Close())
+
+	a, _ := os.ReadFile(filepath.Join(dir, "first.log"))
+	b, _ := os.ReadFile(filepath.Join(dir, "second.log"))
+	assert.Equal(t, 8*200, strings.Count(string(a)+string(b), "msg=record"))
+
+	_, err = first.Write([]byte("late"))
+	assert.ErrorIs(t, err, ErrWriterClosed)
+}
+
+// writerFunc is a writer of a type that cannot be compared.
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+func TestLogger_SwapOutputWaits(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	blocking := writerFunc(
+		func(p []byte) (int, error) {
+			once.Do(func() { close(entered) })
+			<-release
+			return len(p), nil
+		},
+	)
+	l := New(WithOutput(blocking))
+	go l.Info("slow")
+	<-entered
+
+	// The record blocked in the previous output is waited for; new records are not
+	next := &syncBuffer{}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, l.SwapOutput(ctx, next), context.DeadlineExceeded)
+	l.Info("new")
+	assert.Contains(t, next.String(), "msg=new")
+
+	close(release)
+	require.NoError(t, l.SwapOutput(context.Background(), blocking))
+	assert.NotPanics(
+		t, func() {
+			require.NoError(t, l.SwapOutput(context.Background(), writerFunc(io.Discard.Write)))
+		}, "writers that cannot be compared",
+	)
+}
@@ -0,0 +1,178 @@
+package slogx
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// FlightRecorder keeps records below the configured level in memory instead of
+// dropping them, and writes them out when something goes wrong: when a record at the
+// Trigger level is logged in the same scope, when Logger.DumpFlightRecorder is called,
+// and before Logger.FatalContext exits (which dumps every buffer it can reach).
+//
+// The scope is the context: records logged with a context from WithFlightScope (e.g.
+// one per request) are kept in that context's own buffer and dumped only by a trigger
+// logged with it; all other records share the logger's buffer. That buffer is shared
+// by every goroutine and derived logger, so on a busy logger it holds the latest
+// records of everything, not those that led to a particular error.
+//
+// The recorder is not free: Enabled reports true for the levels it keeps, so log
+// calls below the level build their record and copy it into the buffer instead of
+// returning early. Keep Level close to the logging level on hot paths.
+//
+// Disabling the recorder (Size 0) drops the records kept in the logger's buffer, and
+// records still kept in scoped buffers are no longer written.
+type FlightRecorder struct {
+	// Size is the number of records kept per scope, dropping the oldest first.
+	// Zero disables the recorder.
+	Size int
+	// Level is the lowest level recorded; nil records every level.
+	Level slog.Leveler
+	// Trigger is the level that dumps the scope; nil means slog.LevelError.
+	Trigger slog.Leveler
+}
+
+func (fr FlightRecorder) enabled() bool {
+	return fr.Size > 0
+}
+
+// records reports whether records at level are kept by the recorder.
+func (fr FlightRecorder) records(level slog.Level) bool {
+	return fr.enabled() && (fr.Level == nil || level >= fr.Level.Level())
+}
+
+// triggers reports whether a record at level dumps its scope.
+func (fr FlightRecorder) triggers(level slog.Level) bool {
+	if fr.Trigger == nil {
+		return level >= slog.LevelError
+	}
+	return level >= fr.Trigger.Level()
+}
+
+// flightEntry is a kept record with what it was logged with.
+type flightEntry struct {
+	r   slog.Record
+	h   *DynamicHandler
+	ctx context.Context
+}
+
+// flightBuffer is a ring buffer of kept records.
+type flightBuffer struct {
+	mu      sync.Mutex
+	entries []flightEntry
+	start   int
+	n       int
+}
+
+// add appends e, dropping the oldest record if the buffer holds size records.
+func (b *flightBuffer) add(e flightEntry, size int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.entries) != size {
+		// The size was changed with UpdateConfig: keep the newest records
+		kept := b.drainLocked()
+		if len(kept) > size {
+			kept = kept[len(kept)-size:]
+		}
+		b.entries = make([]flightEntry, size)
+		b.n = copy(b.entries, kept)
+	}
+
+	if b.n < size {
+		b.entries[(b.start+b.n)%size] = e
+		b.n++
+		return
+	}
+	b.entries[b.start] = e
+	b.start = (b.start + 1) % size
+}
+
+// reset drops the kept records.
+func (b *flightBuffer) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries, b.start, b.n = nil, 0, 0
+}
+
+// drain removes and returns the kept records, oldest first.
+func (b *flightBuffer) drain() []flightEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.drainLocked()
+}
+
+func (b *flightBuffer) drainLocked() []flightEntry {
+	out := make([]flightEntry, 0, b.n)
+	for i := 0; i < b.n; i++ {
+		j := (b.start + i) % len(b.entries)
+		out = append(out, b.entries[j])
+		b.entries[j] = flightEntry{}
+	}
+	b.start, b.n = 0, 0
+	return out
+}
+
+// dump writes the kept records through the handlers they were logged with.
+func (b *flightBuffer) dump() {
+	for _, e := range b.drain() {
+		_ = e.h.handle(e.ctx, e.r)
+	}
+}
+
+type flightScopeKey struct{}
+
+// WithFlightScope returns a context with its own flight recorder buffer (see
+// FlightRecorder). Records logged with it, or with contexts derived from it, are
+// dumped only by a trigger logged in the same scope.
+func WithFlightScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, flightScopeKey{}, &flightBuffer{})
+}
+
+// flightBufferFor returns the buffer of the scope of ctx, falling back to the logger's.
+func (s *loggerState) flightBufferFor(ctx context.Context) *flightBuffer {
+	if ctx != nil {
+		if b, ok := ctx.Value(flightScopeKey{}).(*flightBuffer); ok {
+			return b
+		}
+	}
+	return &s.flight
+}
+
+// dumpFlightRecorders writes the records kept in the scope of ctx and in the logger's buffer.
+func (l *Logger) dumpFlightRecorders(ctx context.Context) {
+	if l.state == nil {
+		return
+	}
+	if b := l.state.flightBufferFor(ctx); b != &l.state.flight {
+		l.dumpFlightBuffer(b)
+	}
+	l.dumpFlightBuffer(&l.state.flight)
+}
+
+// DumpFlightRecorder writes the records kept by the flight recorder in the scope of
+// ctx (see WithFlightScope), or in the logger's buffer if ctx has no scope.
+func (l *Logger) DumpFlightRecorder(ctx context.Context) {
+	if l.state != nil {
+		l.dumpFlightBuffer(l.state.flightBufferFor(ctx))
+	}
+}
+
+// flightRecorderChanged drops the records of the logger's buffer when the flight
+// recorder is disabled, as they would never be written.
+func (s *loggerState) flightRecorderChanged(oldFR, newFR FlightRecorder) {
+	if oldFR.enabled() && !newFR.enabled() {
+		s.flight.reset()
+	}
+}
+
+// dumpFlightBuffer writes the records kept in b, or drops them if the recorder has
+// been disabled since.
+func (l *Logger) dumpFlightBuffer(b *flightBuffer) {
+	if !l.cfgPtr.Load().FlightRecorder.enabled() {
+		b.reset()
+		return
+	}
+	b.dump()
+}
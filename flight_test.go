@@ -0,0 +1,122 @@
+package slogx
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// messages returns the msg of every JSON record written to buf.
+func messages(t *testing.T, buf string) []string {
+	t.Helper()
+	var msgs []string
+	for _, m := range jsonLines(t, buf) {
+		msgs = append(msgs, m["msg"].(string))
+	}
+	return msgs
+}
+
+func TestFlightRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(
+		WithOutput(&buf), WithFormat(FormatJSON), WithLevel(slog.LevelInfo),
+		WithFlightRecorder(FlightRecorder{Size: 3}),
+	)
+	ctx := context.Background()
+	assert.True(t, l.Enabled(ctx, slog.LevelDebug))
+
+	db := l.Named("db").With("host", "db1")
+	for _, msg := range []string{"d0", "d1", "d2"} {
+		db.Debug(msg)
+	}
+	l.Log(ctx, LevelTrace, "t3")
+	l.Info("info")
+	assert.Equal(t, []string{"info"}, messages(t, buf.String()), "kept records are not written")
+
+	l.Error("boom")
+	assert.Equal(t, []string{"info", "d1", "d2", "t3", "boom"}, messages(t, buf.String()))
+	lines := jsonLines(t, buf.String())
+	assert.Equal(t, "DEBUG", lines[1]["level"])
+	assert.Equal(t, "db", lines[1][LoggerKey], "kept records are written through their logger")
+	assert.Equal(t, "db1", lines[1]["host"])
+
+	// The buffer was emptied by the dump
+	buf.Reset()
+	l.Error("boom again")
+	assert.Equal(t, []string{"boom again"}, messages(t, buf.String()))
+
+	// Disabled: records below the level are dropped again
+	l.UpdateConfig(func(c *Config) { c.FlightRecorder = FlightRecorder{} })
+	assert.False(t, l.Enabled(ctx, slog.LevelDebug))
+}
+
+func TestFlightRecorder_Scopes(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(
+		WithOutput(&buf), WithFormat(FormatJSON), WithLevel(slog.LevelInfo),
+		WithFlightRecorder(FlightRecorder{Size: 10, Level: slog.LevelDebug, Trigger: slog.LevelWarn}),
+	)
+	req1 := WithFlightScope(context.Background())
+	req2 := WithFlightScope(context.Background())
+
+	l.DebugContext(req1, "req1 debug")
+	l.DebugContext(req2, "req2 debug")
+	l.Debug("global debug")
+	l.Log(req1, LevelTrace, "below the recorder level")
+
+	l.WarnContext(req1, "req1 warn")
+	assert.Equal(t, []string{"req1 debug", "req1 warn"}, messages(t, buf.String()))
+
+	buf.Reset()
+	l.DumpFlightRecorder(context.Background())
+	assert.Equal(t, []string{"global debug"}, messages(t, buf.String()))
+
+	buf.Reset()
+	l.DumpFlightRecorder(req2)
+	assert.Equal(t, []string{"req2 debug"}, messages(t, buf.String()))
+
+	// FatalContext dumps the scope and the logger buffer
+	buf.Reset()
+	l.DebugContext(req1, "scoped")
+	l.Debug("unscoped")
+	l.dumpFlightRecorders(req1)
+	assert.Equal(t, []string{"scoped", "unscoped"}, messages(t, buf.String()))
+}
+
+func TestFlightRecorder_Resize(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(
+		WithOutput(&buf), WithFormat(FormatJSON), WithLevel(slog.LevelInfo),
+		WithFlightRecorder(FlightRecorder{Size: 4}),
+	)
+	for _, msg := range []string{"a", "b", "c"} {
+		l.Debug(msg)
+	}
+
+	l.UpdateConfig(func(c *Config) { c.FlightRecorder.Size = 2 })
+	l.Debug("d")
+	l.DumpFlightRecorder(context.Background())
+	assert.Equal(t, []string{"c", "d"}, messages(t, buf.String()), "the newest records are kept")
+
+	require.ErrorIs(
+		t, l.TryUpdateConfig(func(c *Config) { c.FlightRecorder.Size = -1 }), ErrInvalidConfig,
+	)
+
+	// Size 0 disables the recorder and drops what it kept
+	buf.Reset()
+	scope := WithFlightScope(context.Background())
+	l.Debug("global")
+	l.DebugContext(scope, "scoped")
+	l.UpdateConfig(func(c *Config) { c.FlightRecorder.Size = 0 })
+	assert.Zero(t, l.state.flight.n)
+	l.dumpFlightRecorders(scope)
+	assert.Empty(t, buf.String())
+
+	l.UpdateConfig(func(c *Config) { c.FlightRecorder.Size = 2 })
+	l.Error("boom")
+	assert.Equal(t, []string{"boom"}, messages(t, buf.String()), "nothing is kept across a disable")
+}
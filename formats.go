@@ -0,0 +1,90 @@
+package slogx
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// FormatConstructor creates the handler that writes records of a Format to w.
+// opts carries the level and the ReplaceAttr function implementing masking,
+// removal and level names, so constructors must pass it on.
+type FormatConstructor func(w io.Writer, opts *slog.HandlerOptions) slog.Handler
+
+// formats holds the built-in and registered format constructors.
+var formats namedRegistry[Format, FormatConstructor]
+
+func init() {
+	builtins := []struct {
+		format Format
+		name   string
+		ctor   FormatConstructor
+	}{
+		{FormatText, "text", func(w io.Writer, o *slog.HandlerOptions) slog.Handler { return slog.NewTextHandler(w, o) }},
+		{FormatJSON, "json", func(w io.Writer, o *slog.HandlerOptions) slog.Handler { return slog.NewJSONHandler(w, o) }},
+		{FormatConsole, "console", newConsoleHandler},
+		{FormatLogfmt, "logfmt", newLogfmtHandler},
+		{FormatGELF, "gelf", newGELFHandler},
+		{FormatCBOR, "cbor", newCBORHandler},
+	}
+	for _, b := range builtins {
+		if formats.register(b.name, b.ctor) != b.format {
+			panic("slogx: built-in formats must be registered in iota order")
+		}
+	}
+}
+
+// RegisterFormat registers a named output format and returns its Format value,
+// which can then be used in WithFormat, Sink.Format, UpdateConfig and ParseFormat.
+//
+// Names are case-insensitive. Registering an existing name (including a built-in
+// one) replaces its constructor and returns the same Format.
+func RegisterFormat(name string, ctor FormatConstructor) Format {
+	if normalizeRegistryName(name) == "" || ctor == nil {
+		panic("slogx: RegisterFormat requires a name and a constructor")
+	}
+	return formats.register(name, ctor)
+}
+
+// LookupFormat returns the Format registered under name (case-insensitive).
+func LookupFormat(name string) (Format, bool) {
+	return formats.lookup(name)
+}
+
+// String returns the registered name of the format.
+func (f Format) String() string {
+	if name, ok := formats.name(f); ok {
+		return name
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// MarshalText implements encoding.TextMarshaler so formats are written by name.
+func (f Format) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler so formats can be read by name
+// from configuration files.
+func (f *Format) UnmarshalText(text []byte) error {
+	format, ok := LookupFormat(string(text))
+	if !ok {
+		return fmt.Errorf("slogx: unknown format %q", text)
+	}
+	*f = format
+	return nil
+}
+
+// registered reports whether f has a constructor in the format registry.
+func (f Format) registered() bool {
+	_, ok := formats.value(f)
+	return ok
+}
+
+// newFormatHandler builds the handler for format, falling back to FormatText for unknown values.
+func newFormatHandler(format Format, w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	if ctor, ok := formats.value(format); ok {
+		return ctor(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
@@ -0,0 +1,248 @@
+package slogx
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatRegistry(t *testing.T) {
+	for _, name := range []string{"text", "json", "console", "logfmt", "gelf", "cbor"} {
+		f, ok := LookupFormat(name)
+		require.True(t, ok, name)
+		assert.Equal(t, name, f.String())
+	}
+	assert.Equal(t, FormatGELF, ParseFormat(" GELF "))
+	assert.Equal(t, FormatText, ParseFormat("unknown"))
+
+	called := false
+	t.Cleanup(func() { formats.unregister("test-upper") })
+	custom := RegisterFormat(
+		"test-upper", func(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+			called = true
+			return slog.NewJSONHandler(w, opts)
+		},
+	)
+	assert.Equal(t, custom, RegisterFormat("Test-Upper", func(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+		called = true
+		return slog.NewJSONHandler(w, opts)
+	}), "re-registering a name keeps its Format")
+
+	buf := &bytes.Buffer{}
+	l := New(WithOutput(buf), WithFormat(ParseFormat("test-upper")), WithMaskKey("password", MaskDefault))
+	l.Info("login", "password", "secret")
+	assert.True(t, called)
+	assert.Contains(t, buf.String(), `"password":"[MASKED]"`, "custom formats must honour ReplaceAttr")
+
+	var f Format
+	require.NoError(t, json.Unmarshal([]byte(`"logfmt"`), &f))
+	assert.Equal(t, FormatLogfmt, f)
+	assert.Error(t, json.Unmarshal([]byte(`"yaml"`), &f))
+	data, err := json.Marshal(FormatCBOR)
+	require.NoError(t, err)
+	assert.Equal(t, `"cbor"`, string(data))
+}
+
+func TestLogfmtFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(WithOutput(buf), WithFormat(FormatLogfmt), WithMaskKey("email", MaskEmail))
+
+	l.WithGroup("req").Info(
+		"user login",
+		"path", "/a b",
+		"quote", `say "hi"`,
+		"empty", "",
+		"eq", "a=b",
+		"email", "antonioh@gmail.com",
+		"took", 1500*time.Millisecond,
+		slog.Group("user", "id", 42),
+	)
+
+	line := buf.String()
+	assert.Regexp(t, `^time=\S+ level=INFO msg="user login" `, line)
+	assert.Contains(t, line, ` req.path="/a b"`)
+	assert.Contains(t, line, ` req.quote="say \"hi\""`)
+	assert.Contains(t, line, ` req.empty=""`)
+	assert.Contains(t, line, ` req.eq="a=b"`)
+	assert.Contains(t, line, ` req.email=an***h@gmail.com`)
+	assert.Contains(t, line, ` req.took=1.5s`)
+	assert.Contains(t, line, ` req.user.id=42`)
+	assert.True(t, strings.HasSuffix(line, "\n"))
+
+	buf.Reset()
+	l.Info("multi\nline", "bad key", 1)
+	assert.Contains(t, buf.String(), `msg="multi\nline" bad_key=1`)
+}
+
+func TestGELFFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(WithOutput(buf), WithFormat(FormatGELF), WithLevel(LevelTrace))
+
+	l.WithGroup("req").Error("request failed\ndetails", "id", 7, "status", 500, "err", errors.New("boom"), "ratio", math.Inf(1))
+
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	assert.Equal(t, "1.1", m["version"])
+	assert.Equal(t, gelfHost, m["host"])
+	assert.Equal(t, "request failed", m["short_message"])
+	assert.Equal(t, "request failed\ndetails", m["full_message"])
+	assert.Equal(t, float64(3), m["level"])
+	assert.Equal(t, "ERROR", m["_level_name"])
+	assert.Equal(t, float64(7), m["_req_id"])
+	assert.Equal(t, float64(500), m["_req_status"])
+	assert.Equal(t, "boom", m["_req_err"])
+	assert.Equal(t, "+Inf", m["_req_ratio"])
+	assert.IsType(t, float64(0), m["timestamp"])
+
+	buf.Reset()
+	l.TraceContext(context.Background(), "trace", "id", "x")
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	assert.Equal(t, float64(7), m["level"])
+	assert.Equal(t, "TRACE", m["_level_name"])
+	assert.Equal(t, "x", m["__id"], "the reserved _id field must be renamed")
+}
+
+func TestCBORFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(WithOutput(buf), WithFormat(FormatCBOR), WithMaskKey("card", MaskCard))
+
+	at := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	l.WithGroup("req").Info(
+		"paid",
+		"n", -25,
+		"u", uint64(1<<40),
+		"f", 1.5,
+		"ok", true,
+		"took", time.Second,
+		"at", at,
+		"raw", []byte{1, 2},
+		"card", "4276 1234 5678 0000",
+		"err", errors.New("declined"),
+		"items", []string{"a", "b"},
+	)
+	l.Info("second")
+
+	d := &cborDecoder{data: buf.Bytes()}
+	first, ok := d.next().(map[string]any)
+	require.True(t, ok)
+	second, ok := d.next().(map[string]any)
+	require.True(t, ok)
+	assert.Empty(t, d.data, "records must be written back to back")
+
+	assert.Equal(t, "INFO", first["level"])
+	assert.Equal(t, "paid", first["msg"])
+	assert.IsType(t, cborEpoch(0), first["time"])
+
+	req, ok := first["req"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, int64(-25), req["n"])
+	assert.Equal(t, int64(1<<40), req["u"])
+	assert.Equal(t, 1.5, req["f"])
+	assert.Equal(t, true, req["ok"])
+	assert.Equal(t, int64(time.Second), req["took"])
+	assert.Equal(t, cborEpoch(float64(at.Unix())), req["at"])
+	assert.Equal(t, []byte{1, 2}, req["raw"])
+	assert.Equal(t, "4276 **** **** 0000", req["card"])
+	assert.Equal(t, "declined", req["err"])
+	assert.Equal(t, []any{"a", "b"}, req["items"])
+
+	assert.Equal(t, "second", second["msg"])
+}
+
+// cborEpoch is a decoded tag 1 timestamp.
+type cborEpoch float64
+
+// cborDecoder decodes the subset of CBOR produced by encodeCBOR.
+type cborDecoder struct {
+	data []byte
+}
+
+func (d *cborDecoder) next() any {
+	b := d.data[0]
+	d.data = d.data[1:]
+	major, info := b>>5, b&0x1f
+
+	if major == 7 {
+		switch info {
+		case 20:
+			return false
+		case 21:
+			return true
+		case 22:
+			return nil
+		case 27:
+			f := math.Float64frombits(binary.BigEndian.Uint64(d.data))
+			d.data = d.data[8:]
+			return f
+		}
+		panic(fmt.Sprintf("unsupported simple value %d", info))
+	}
+
+	n := d.arg(info)
+	switch major {
+	case 0:
+		return int64(n)
+	case 1:
+		return -1 - int64(n)
+	case 2:
+		v := append([]byte(nil), d.data[:n]...)
+		d.data = d.data[n:]
+		return v
+	case 3:
+		v := string(d.data[:n])
+		d.data = d.data[n:]
+		return v
+	case 4:
+		v := make([]any, n)
+		for i := range v {
+			v[i] = d.next()
+		}
+		return v
+	case 5:
+		v := make(map[string]any, n)
+		for i := uint64(0); i < n; i++ {
+			k := d.next().(string)
+			v[k] = d.next()
+		}
+		return v
+	case 6:
+		if n != cborTagEpoch {
+			panic(fmt.Sprintf("unsupported tag %d", n))
+		}
+		return cborEpoch(d.next().(float64))
+	}
+	panic(fmt.Sprintf("unsupported major type %d", major))
+}
+
+func (d *cborDecoder) arg(info byte) uint64 {
+	var n uint64
+	switch {
+	case info < 24:
+		return uint64(info)
+	case info == 24:
+		n = uint64(d.data[0])
+		d.data = d.data[1:]
+	case info == 25:
+		n = uint64(binary.BigEndian.Uint16(d.data))
+		d.data = d.data[2:]
+	case info == 26:
+		n = uint64(binary.BigEndian.Uint32(d.data))
+		d.data = d.data[4:]
+	case info == 27:
+		n = binary.BigEndian.Uint64(d.data)
+		d.data = d.data[8:]
+	}
+	return n
+}
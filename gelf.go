@@ -0,0 +1,154 @@
+package slogx
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const gelfVersion = "1.1"
+
+// gelfHost is the "host" field of every GELF message.
+var gelfHost = func() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		return "unknown"
+	}
+	return host
+}()
+
+// newGELFHandler returns the handler behind FormatGELF.
+func newGELFHandler(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	return newEncodeHandler(w, opts, encodeGELF)
+}
+
+// encodeGELF renders a record as a GELF 1.1 JSON message followed by a newline.
+//
+// The message becomes short_message (its first line) and full_message (when it
+// spans several lines), the level is mapped to a syslog severity and the rendered
+// level name is kept in _level_name. User attributes become additional fields:
+// keys are prefixed with '_', group members are joined with '_', characters
+// outside [A-Za-z0-9_.-] are replaced and the reserved "_id" is renamed to "__id".
+// GELF only allows strings and numbers, so other values are sent as strings.
+func encodeGELF(buf []byte, e *entry) []byte {
+	ts := time.Now()
+	if t, ok := timeValue(e.time); ok {
+		ts = t
+	}
+
+	msg := ""
+	if e.msg.Key != "" {
+		msg = e.msg.Value.String()
+	}
+	short, _, multiline := strings.Cut(msg, "\n")
+	if short == "" {
+		short = "-"
+	}
+
+	buf = append(buf, `{"version":"`+gelfVersion+`","host":`...)
+	buf = appendJSONString(buf, gelfHost)
+	buf = append(buf, `,"short_message":`...)
+	buf = appendJSONString(buf, short)
+	if multiline {
+		buf = append(buf, `,"full_message":`...)
+		buf = appendJSONString(buf, msg)
+	}
+	buf = append(buf, `,"timestamp":`...)
+	buf = strconv.AppendFloat(buf, float64(ts.UnixMilli())/1e3, 'f', 3, 64)
+	buf = append(buf, `,"level":`...)
+	buf = strconv.AppendInt(buf, int64(syslogSeverity(e.level)), 10)
+	if e.lvl.Key != "" {
+		buf = append(buf, `,"_level_name":`...)
+		buf = appendJSONString(buf, e.lvl.Value.String())
+	}
+
+	var walk func(prefix string, a slog.Attr)
+	walk = func(prefix string, a slog.Attr) {
+		if a.Value.Kind() == slog.KindGroup {
+			for _, m := range a.Value.Group() {
+				walk(prefix+a.Key+"_", m)
+			}
+			return
+		}
+		buf = append(buf, ',')
+		buf = appendJSONString(buf, gelfFieldName(prefix+a.Key))
+		buf = append(buf, ':')
+		buf = appendGELFValue(buf, a.Value)
+	}
+	for _, a := range e.attrs {
+		walk("", a)
+	}
+
+	return append(buf, "}\n"...)
+}
+
+// syslogSeverity maps slog levels to syslog severities used by GELF.
+func syslogSeverity(l slog.Level) int {
+	switch {
+	case l < slog.LevelInfo:
+		return 7 // debug
+	case l < slog.LevelWarn:
+		return 6 // informational
+	case l < slog.LevelError:
+		return 4 // warning
+	case l < LevelFatal:
+		return 3 // error
+	default:
+		return 2 // critical
+	}
+}
+
+// gelfFieldName turns an attribute key into a valid GELF additional field name.
+func gelfFieldName(key string) string {
+	b := make([]byte, 0, len(key)+1)
+	b = append(b, '_')
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_', c == '.', c == '-':
+			b = append(b, c)
+		default:
+			b = append(b, '_')
+		}
+	}
+	if string(b) == "_id" {
+		return "__id"
+	}
+	return string(b)
+}
+
+func appendGELFValue(buf []byte, v slog.Value) []byte {
+	switch v.Kind() {
+	case slog.KindInt64:
+		return strconv.AppendInt(buf, v.Int64(), 10)
+	case slog.KindUint64:
+		return strconv.AppendUint(buf, v.Uint64(), 10)
+	case slog.KindFloat64:
+		f := v.Float64()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return appendJSONString(buf, strconv.FormatFloat(f, 'g', -1, 64))
+		}
+		return strconv.AppendFloat(buf, f, 'g', -1, 64)
+	case slog.KindTime:
+		return appendJSONString(buf, v.Time().Format(time.RFC3339Nano))
+	case slog.KindDuration:
+		return appendJSONString(buf, v.Duration().String())
+	case slog.KindString:
+		return appendJSONString(buf, v.String())
+	case slog.KindAny:
+		return appendJSONString(buf, anyString(v.Any()))
+	default:
+		return appendJSONString(buf, v.String())
+	}
+}
+
+// appendJSONString appends s as a JSON string literal.
+func appendJSONString(buf []byte, s string) []byte {
+	b, _ := json.Marshal(s)
+	return append(buf, b...)
+}
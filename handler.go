@@ -2,13 +2,14 @@ package slogx
 
 import (
 	"context"
+	"io"
 	"log/slog"
 	"sync/atomic"
 )
 
 // DynamicHandler is a middleware-style slog.Handler implementation that supports
 // dynamic reconfiguration at runtime. It allows hot-swapping log level, output format,
-// masking rules, attribute removal rules, and level name customization.
+// output sinks, masking rules, attribute removal rules, and level name customization.
 //
 // To reduce per-log-call overhead, the handler caches the static handler chain
 // (base handler + WithAttrs + WithGroup). Only context-derived attributes are applied
@@ -18,34 +19,108 @@ import (
 //  1. Context-derived attributes (dynamic, highest priority)
 //  2. Logger.With(...) attributes (cached)
 //  3. Attributes added directly in the log call (slog.Record)
+//
+// Context-derived attributes are collected in this order, a later source replacing
+// an attribute with the same key from an earlier one:
+//  1. Config.ContextKeys
+//  2. Config.ContextExtractors
+//  3. Attributes carried by the context (ContextWith, AppendCtx)
+//  4. The active span (Config.TraceExtractor)
+//
+// Like all other attributes, they go through masking and removal rules.
 type DynamicHandler struct {
-	cfg *atomic.Pointer[Config]
+	cfg   *atomic.Pointer[Config]
+	state *loggerState
 
+	// name is the hierarchical logger name set with Logger.Named ("" for the root logger).
+	name   string
 	attrs  []slog.Attr
 	groups []string
 
-	// cachedHandler stores the fully constructed static handler chain:
-	//   baseHandler -> WithAttrs(attrs) -> WithGroup(groups)
-	// It is rebuilt only when configuration, attrs, or groups change.
-	cachedHandler atomic.Pointer[slog.Handler]
+	// cache stores the fully constructed static handler chain together with the
+	// configuration pointer it was built from. It is rebuilt only when configuration,
+	// attrs, or groups change.
+	cache atomic.Pointer[handlerCache]
+}
 
-	// cachedConfigVersion tracks the last configuration pointer used to build the cache.
-	// If cfg.Load() returns a different pointer, the cache is invalidated.
-	cachedConfigVersion atomic.Pointer[Config]
+// handlerCache is everything a DynamicHandler derives from one configuration.
+type handlerCache struct {
+	// cfg is the configuration the cache was built from. If cfg.Load() returns a
+	// different pointer, the cache is invalidated.
+	cfg *Config
+	// handler is the static handler chain:
+	//   baseHandler -> WithAttrs(attrs) -> WithGroup(groups)
+	handler slog.Handler
+	// level is the minimum level resolved for the logger name (see Config.LoggerLevels).
+	level slog.Level
 }
 
 // Enabled reports whether the record should be logged based on the current
-// dynamic log level stored in the atomic configuration.
+// dynamic log level stored in the atomic configuration (the level configured for
+// the logger name, if any), or on the level override carried by ctx (see ContextWithLevel).
+// Records below that level are also enabled when Config.FlightRecorder keeps them.
 func (h *DynamicHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	return level >= h.cfg.Load().Level
+	cfg := h.cfg.Load()
+	return h.levelEnabled(ctx, cfg, level) || cfg.FlightRecorder.records(level)
+}
+
+// levelEnabled reports whether level passes the level of ctx, the logger name or cfg.
+func (h *DynamicHandler) levelEnabled(ctx context.Context, cfg *Config, level slog.Level) bool {
+	if minLevel, ok := LevelFromContext(ctx); ok {
+		return level >= minLevel
+	}
+	if h.name == "" || len(cfg.LoggerLevels) == 0 {
+		return level >= cfg.Level
+	}
+	return level >= h.getOrBuildCache(cfg).level
 }
 
 // Handle processes a log record using a cached static handler chain.
-// Only context-derived attributes are applied dynamically.
+// With Config.FlightRecorder, records below the level are kept in memory and a
+// trigger record first writes the kept records of its scope.
+// Duplicates within Config.Dedup and records over Config.Sampling or Config.RateLimit
+// are dropped. Only context-derived attributes are applied dynamically.
 func (h *DynamicHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.state != nil {
+		cfg := h.cfg.Load()
+		if fr := cfg.FlightRecorder; fr.enabled() {
+			if !h.levelEnabled(ctx, cfg, r.Level) {
+				if fr.records(r.Level) {
+					h.state.flightBufferFor(ctx).add(flightEntry{r: r.Clone(), h: h, ctx: ctx}, fr.Size)
+				}
+				return nil
+			}
+			if fr.triggers(r.Level) {
+				h.state.flightBufferFor(ctx).dump()
+			}
+		}
+		if h.state.dedup.suppress(ctx, h, cfg, r) || !h.state.throttle.allow(ctx, h, cfg, r) {
+			return nil
+		}
+	}
+	return h.handle(ctx, r)
+}
+
+// handle writes a record that passed the throttle.
+func (h *DynamicHandler) handle(ctx context.Context, r slog.Record) error {
+	// Mark the record as in flight so SwapOutput can wait for it. A retired
+	// generation was replaced by a newer configuration, unless it was copied into it.
 	cfg := h.cfg.Load()
+	for {
+		if cfg.gen.acquire() {
+			defer cfg.gen.release()
+			break
+		}
+		next := h.cfg.Load()
+		retired := next.gen == cfg.gen
+		cfg = next
+		if retired {
+			break
+		}
+	}
 
-	// Step 1: Collect context-derived attributes (highest priority)
+	// Step 1: Collect context-derived attributes (highest priority):
+	// ContextKeys, ContextExtractors, attributes stashed with ContextWith and the active span.
 	// This allows middleware to inject IDs into context that automatically appear in logs.
 	var ctxAttrs []slog.Attr
 	for _, key := range cfg.ContextKeys {
@@ -53,9 +128,24 @@ func (h *DynamicHandler) Handle(ctx context.Context, r slog.Record) error {
 			ctxAttrs = append(ctxAttrs, slog.Any(key, val))
 		}
 	}
+	for _, extract := range cfg.ContextExtractors {
+		// UpdateConfig does not validate, so a nil extractor may get here
+		if extract == nil {
+			continue
+		}
+		for _, a := range extract(ctx) {
+			ctxAttrs = setAttr(ctxAttrs, a)
+		}
+	}
+	for _, a := range ContextAttrs(ctx) {
+		ctxAttrs = setAttr(ctxAttrs, a)
+	}
+	for _, a := range traceAttrs(ctx, cfg.TraceExtractor) {
+		ctxAttrs = setAttr(ctxAttrs, a)
+	}
 
 	// Step 2: Get or rebuild the cached static handler chain
-	base := h.getOrBuildCachedHandler(cfg)
+	base := h.getOrBuildCache(cfg).handler
 
 	// Step 3: Apply context attributes (highest priority)
 	if len(ctxAttrs) > 0 {
@@ -66,35 +156,55 @@ func (h *DynamicHandler) Handle(ctx context.Context, r slog.Record) error {
 	return base.Handle(ctx, r)
 }
 
-// getOrBuildCachedHandler returns the cached handler chain if valid,
-// otherwise rebuilds it and updates the cache.
+// getOrBuildCache returns the cached handler chain and level if valid,
+// otherwise rebuilds them and updates the cache.
 //
 // Cached chain includes:
-//   - JSON/Text handler
+//   - Format handler from the registry (or a fan-out over all Config.Sinks)
 //   - ReplaceAttr
+//   - The logger name attribute (Logger.Named)
 //   - WithAttrs(attrs)
 //   - WithGroup(groups)
 //
 // Context attributes are NOT cached.
-func (h *DynamicHandler) getOrBuildCachedHandler(cfg *Config) slog.Handler {
-	// Fast path: if config pointer matches and cache exists — return it
-	if h.cachedConfigVersion.Load() == cfg {
-		if cached := h.cachedHandler.Load(); cached != nil {
-			return *cached
-		}
+func (h *DynamicHandler) getOrBuildCache(cfg *Config) *handlerCache {
+	// Fast path: if config pointer matches — return the cache
+	if c := h.cache.Load(); c != nil && c.cfg == cfg {
+		return c
 	}
 
 	// Slow path: rebuild the handler chain
-	hOpts := &slog.HandlerOptions{
-		Level:       cfg.Level,
-		ReplaceAttr: h.getReplaceAttr(cfg),
-	}
-
 	var base slog.Handler
-	if cfg.Format == FormatJSON {
-		base = slog.NewJSONHandler(cfg.Output, hOpts)
+	if len(cfg.Sinks) == 0 {
+		// A nil Output is rejected by Validate, but UpdateConfig does not validate
+		out := cfg.Output
+		if out == nil {
+			out = io.Discard
+		}
+		base = h.buildFormatHandler(cfg, out, cfg.Format, cfg.MaskKeys, cfg.RemoveKeys)
 	} else {
-		base = slog.NewTextHandler(cfg.Output, hOpts)
+		fan := &fanoutHandler{sinks: make([]sinkHandler, 0, len(cfg.Sinks))}
+		for _, s := range cfg.Sinks {
+			if s.Output == nil {
+				continue
+			}
+			fan.sinks = append(
+				fan.sinks, sinkHandler{
+					handler: h.buildFormatHandler(
+						cfg, s.Output, s.Format,
+						mergeMaskKeys(cfg.MaskKeys, s.MaskKeys),
+						mergeRemoveKeys(cfg.RemoveKeys, s.RemoveKeys),
+					),
+					level: s.Level,
+				},
+			)
+		}
+		base = fan
+	}
+
+	// Apply the logger name (Logger.Named)
+	if h.name != "" {
+		base = base.WithAttrs([]slog.Attr{slog.String(LoggerKey, h.name)})
 	}
 
 	// Apply WithAttrs (Logger.With(...) attributes)
@@ -108,10 +218,27 @@ func (h *DynamicHandler) getOrBuildCachedHandler(cfg *Config) slog.Handler {
 	}
 
 	// Store in cache
-	h.cachedHandler.Store(&base)
-	h.cachedConfigVersion.Store(cfg)
+	c := &handlerCache{
+		cfg:     cfg,
+		handler: base,
+		level:   resolveLoggerLevel(h.name, cfg.LoggerLevels, cfg.Level),
+	}
+	h.cache.Store(c)
 
-	return base
+	return c
+}
+
+// buildFormatHandler creates the base handler writing to w in the given format,
+// with ReplaceAttr built from the given mask and removal rules.
+func (h *DynamicHandler) buildFormatHandler(
+	cfg *Config, w io.Writer, format Format, maskKeys MaskMap, removeKeys RemoveMap,
+) slog.Handler {
+	hOpts := &slog.HandlerOptions{
+		Level:       cfg.Level,
+		ReplaceAttr: h.getReplaceAttr(cfg, maskKeys, removeKeys),
+	}
+
+	return newFormatHandler(format, h.state.lockedWriter(w), hOpts)
 }
 
 // WithAttrs returns a new DynamicHandler with additional attributes appended.
@@ -123,6 +250,8 @@ func (h *DynamicHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 
 	return &DynamicHandler{
 		cfg:    h.cfg,
+		state:  h.state,
+		name:   h.name,
 		attrs:  newAttrs,
 		groups: h.groups,
 	}
@@ -137,28 +266,61 @@ func (h *DynamicHandler) WithGroup(name string) slog.Handler {
 
 	return &DynamicHandler{
 		cfg:    h.cfg,
+		state:  h.state,
+		name:   h.name,
 		attrs:  h.attrs,
 		groups: newGroups,
 	}
 }
 
+// withName returns a new DynamicHandler for the child logger name.
+// Cache is invalidated because the handler chain and the level change.
+func (h *DynamicHandler) withName(name string) *DynamicHandler {
+	if h.name != "" {
+		name = h.name + pathSep + name
+	}
+
+	return &DynamicHandler{
+		cfg:    h.cfg,
+		state:  h.state,
+		name:   name,
+		attrs:  h.attrs,
+		groups: h.groups,
+	}
+}
+
 // getReplaceAttr returns a transformation function used by slog.HandlerOptions.
 // It performs:
 //
 //	Attribute removal (RemoveKeys)
 //	Attribute masking (MaskKeys)
+//	Redaction of nested fields in structs, maps and slices (slog.Any values)
+//	Content scanning of string values, errors and the message (Scan, ScanPatterns)
 //	Level name customization (LevelNames)
-func (h *DynamicHandler) getReplaceAttr(cfg *Config) func([]string, slog.Attr) slog.Attr {
+//
+// RemoveKeys and MaskKeys are matched against the full group path of the attribute
+// (see pathMatcher). The rule sets are compiled once here, i.e. whenever the cached
+// handler chain is rebuilt, not on every log call. They are passed separately from
+// cfg because every sink may extend the global rules.
+func (h *DynamicHandler) getReplaceAttr(
+	cfg *Config, maskRules MaskMap, removeRules RemoveMap,
+) func([]string, slog.Attr) slog.Attr {
+	removeKeys := compilePaths(removeRules)
+	maskKeys := compilePaths(maskRules)
+	masker := newConfigMasker(cfg)
+	scanner := newContentScanner(cfg, masker)
+	red := &redactor{mask: maskKeys, remove: removeKeys, masker: masker, scan: scanner}
+
 	return func(groups []string, a slog.Attr) slog.Attr {
 
 		// Attribute removal: Check if the key is in the removal set
-		if _, shouldRemove := cfg.RemoveKeys[a.Key]; shouldRemove {
+		if _, shouldRemove := removeKeys.match(groups, a.Key); shouldRemove {
 			return slog.Attr{}
 		}
 
 		// Attribute masking: Apply data redaction rules
-		if mType, ok := cfg.MaskKeys[a.Key]; ok {
-			a.Value = slog.AnyValue(cfg.Masker.Mask(a.Value.Any(), mType))
+		if mType, ok := maskKeys.match(groups, a.Key); ok {
+			a.Value = slog.AnyValue(masker.Mask(a.Value.Any(), mType))
 			return a
 		}
 
@@ -166,6 +328,33 @@ func (h *DynamicHandler) getReplaceAttr(cfg *Config) func([]string, slog.Attr) s
 		if a.Key == slog.LevelKey {
 			if lvl, ok := a.Value.Any().(slog.Level); ok {
 				a.Value = slog.StringValue(getLevelName(lvl, cfg.LevelNames))
+				return a
+			}
+		}
+
+		switch a.Value.Kind() {
+		case slog.KindString:
+			// Content scanning: redact sensitive data regardless of the key (includes the message)
+			if scanner != nil {
+				if s, changed := scanner.scan(a.Value.String()); changed {
+					a.Value = slog.StringValue(s)
+				}
+			}
+
+		case slog.KindAny:
+			// Errors are rendered as text, so scan their message as well
+			if err, ok := a.Value.Any().(error); ok {
+				if scanner != nil {
+					if s, changed := scanner.scan(err.Error()); changed {
+						a.Value = slog.StringValue(s)
+					}
+				}
+				return a
+			}
+
+			// Nested redaction: walk composite values and apply the same rules to their fields
+			if v, changed := red.redact(groups, a.Key, a.Value.Any()); changed {
+				a.Value = slog.AnyValue(v)
 			}
 		}
 
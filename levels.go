@@ -1,7 +1,9 @@
 package slogx
 
 import (
+	"fmt"
 	"log/slog"
+	"strconv"
 	"strings"
 )
 
@@ -12,6 +14,9 @@ const (
 	LevelFatal = slog.Level(12)
 )
 
+// LoggerKey is the attribute key of the logger name set with Logger.Named.
+const LoggerKey = "logger"
+
 var defaultLevelNames = LevelNames{
 	LevelTrace: "TRACE",
 	LevelFatal: "FATAL",
@@ -25,3 +30,42 @@ func getLevelName(l slog.Level, customNames LevelNames) string {
 
 	return strings.ToUpper(l.String())
 }
+
+// resolveLoggerLevel returns the level for a hierarchical logger name: the entry for
+// the name itself, else for its closest parent ("http.client" -> "http"), else def.
+func resolveLoggerLevel(name string, levels map[string]slog.Level, def slog.Level) slog.Level {
+	for name != "" {
+		if l, ok := levels[name]; ok {
+			return l
+		}
+		i := strings.LastIndex(name, pathSep)
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return def
+}
+
+// ParseLevel parses a level written as a custom name from names or the default level
+// names (case-insensitive, e.g. "trace", "FATAL"), as a slog level name with an optional
+// offset ("DEBUG", "info+2") or as a number ("-8").
+func ParseLevel(s string, names LevelNames) (slog.Level, error) {
+	s = strings.TrimSpace(s)
+	for _, m := range []LevelNames{names, defaultLevelNames} {
+		for l, name := range m {
+			if strings.EqualFold(name, s) {
+				return l, nil
+			}
+		}
+	}
+
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(s)); err == nil {
+		return l, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return slog.Level(n), nil
+	}
+	return 0, fmt.Errorf("slogx: unknown level %q", s)
+}
@@ -0,0 +1,437 @@
+package slogx
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// EnvPrefix is the prefix of the environment variables read by LoadConfig.
+const EnvPrefix = "SLOGX_"
+
+// defaultWatchInterval is how often WatchConfig checks the file when no interval is given.
+const defaultWatchInterval = 2 * time.Second
+
+// configSpec is the declarative form of the runtime-tunable part of Config, as read
+// from a file or environment variables. It has the shape of the AdminHandler GET
+// document. Every field that is set replaces the configured value, except level
+// names, which are added to the configured ones.
+type configSpec struct {
+	// Version is accepted so that a saved GET document can be used as is; it is ignored.
+	Version *uint64 `json:"version"`
+
+	Level        *string             `json:"level"`
+	Format       *Format             `json:"format"`
+	MaskKeys     map[string]MaskType `json:"mask_keys"`
+	RemoveKeys   *[]string           `json:"remove_keys"`
+	LevelNames   map[string]string   `json:"level_names"`
+	ContextKeys  *[]string           `json:"context_keys"`
+	LoggerLevels map[string]string   `json:"logger_levels"`
+}
+
+// apply writes the spec into c. Level names are applied first, so levels may use them.
+func (s *configSpec) apply(c *Config) error {
+	for key, name := range s.LevelNames {
+		l, err := ParseLevel(key, c.LevelNames)
+		if err != nil {
+			return fmt.Errorf("level_names: %w", err)
+		}
+		c.LevelNames[l] = name
+	}
+	if s.Level != nil {
+		l, err := ParseLevel(*s.Level, c.LevelNames)
+		if err != nil {
+			return fmt.Errorf("level: %w", err)
+		}
+		c.Level = l
+	}
+	if s.Format != nil {
+		c.Format = *s.Format
+	}
+	if s.MaskKeys != nil {
+		c.MaskKeys = make(MaskMap, len(s.MaskKeys))
+		for k, t := range s.MaskKeys {
+			c.MaskKeys[k] = t
+		}
+	}
+	if s.RemoveKeys != nil {
+		c.RemoveKeys = make(RemoveMap, len(*s.RemoveKeys))
+		for _, k := range *s.RemoveKeys {
+			c.RemoveKeys[k] = struct{}{}
+		}
+	}
+	if s.ContextKeys != nil {
+		c.ContextKeys = append([]string(nil), *s.ContextKeys...)
+	}
+	if s.LoggerLevels != nil {
+		c.LoggerLevels = make(map[string]slog.Level, len(s.LoggerLevels))
+		for name, lvl := range s.LoggerLevels {
+			l, err := ParseLevel(lvl, c.LevelNames)
+			if err != nil {
+				return fmt.Errorf("logger_levels.%s: %w", name, err)
+			}
+			c.LoggerLevels[name] = l
+		}
+	}
+	return nil
+}
+
+// merge returns the spec with over applied on top of it. Scalars and lists set in
+// over replace those of s, while map entries and remove keys are merged key by key,
+// so that one environment variable does not drop the other rules of the file.
+func (s *configSpec) merge(over *configSpec) *configSpec {
+	m := *s
+	if over.Level != nil {
+		m.Level = over.Level
+	}
+	if over.Format != nil {
+		m.Format = over.Format
+	}
+	if over.ContextKeys != nil {
+		m.ContextKeys = over.ContextKeys
+	}
+	m.MaskKeys = mergeSpecMap(s.MaskKeys, over.MaskKeys)
+	m.LevelNames = mergeSpecMap(s.LevelNames, over.LevelNames)
+	m.LoggerLevels = mergeSpecMap(s.LoggerLevels, over.LoggerLevels)
+	if over.RemoveKeys != nil {
+		keys := append([]string(nil), *over.RemoveKeys...)
+		if s.RemoveKeys != nil {
+			keys = append(append([]string(nil), *s.RemoveKeys...), keys...)
+		}
+		m.RemoveKeys = &keys
+	}
+	return &m
+}
+
+// mergeSpecMap returns the entries of base and over, over taking precedence.
+// It is nil only if both are nil, so that "not set" is kept.
+func mergeSpecMap[V any](base, over map[string]V) map[string]V {
+	if over == nil {
+		return base
+	}
+	m := make(map[string]V, len(base)+len(over))
+	for k, v := range base {
+		m[k] = v
+	}
+	for k, v := range over {
+		m[k] = v
+	}
+	return m
+}
+
+// applySpecChanges copies to dst the fields a configSpec can set that differ between
+// prev and next, entry by entry for maps. Fields equal in both are left as dst has them.
+func applySpecChanges(dst, prev, next *Config) {
+	if next.Level != prev.Level {
+		dst.Level = next.Level
+	}
+	if next.Format != prev.Format {
+		dst.Format = next.Format
+	}
+	if !slices.Equal(next.ContextKeys, prev.ContextKeys) {
+		dst.ContextKeys = append([]string(nil), next.ContextKeys...)
+	}
+	dst.MaskKeys = applyMapChanges(dst.MaskKeys, prev.MaskKeys, next.MaskKeys)
+	dst.RemoveKeys = applyMapChanges(dst.RemoveKeys, prev.RemoveKeys, next.RemoveKeys)
+	dst.LevelNames = applyMapChanges(dst.LevelNames, prev.LevelNames, next.LevelNames)
+	dst.LoggerLevels = applyMapChanges(dst.LoggerLevels, prev.LoggerLevels, next.LoggerLevels)
+}
+
+// applyMapChanges sets in dst the entries added or changed from prev to next and
+// deletes those removed. It returns dst, allocated if it was nil.
+func applyMapChanges[M ~map[K]V, K, V comparable](dst, prev, next M) M {
+	if dst == nil {
+		dst = make(M, len(next))
+	}
+	for k, v := range next {
+		if pv, ok := prev[k]; !ok || pv != v {
+			dst[k] = v
+		}
+	}
+	for k := range prev {
+		if _, ok := next[k]; !ok {
+			delete(dst, k)
+		}
+	}
+	return dst
+}
+
+// LoadConfig builds a Config from the defaults of New, the file at path (skipped if
+// path is empty) and the SLOGX_* environment variables, which take precedence.
+// Map entries (masks, level names, logger levels) and removed keys are merged key by
+// key, so SLOGX_MASK_phone adds to the mask_keys of the file instead of replacing them.
+//
+// Files ending in .json have the shape of the AdminHandler GET document:
+//
+//	{"level": "debug", "format": "json", "mask_keys": {"email": "email"}, "remove_keys": ["password"]}
+//
+// Any other file is read as KEY=VALUE lines using the environment variable names
+// (blank lines and lines starting with '#' are ignored):
+//
+//	SLOGX_LEVEL=debug                  level name, slog level ("info+2") or number
+//	SLOGX_FORMAT=json                  registered format name
+//	SLOGX_MASK_email=email             mask the key with the named mask type
+//	SLOGX_REMOVE=password,token        keys to remove
+//	SLOGX_CONTEXT_KEYS=request_id      context keys to log
+//	SLOGX_LOGGER_LEVELS=db=debug,http=warn
+//
+// Unknown SLOGX_* names are reported as errors to catch typos, and the resulting
+// configuration must pass Config.Validate.
+func LoadConfig(path string) (*Config, error) {
+	cfg := defaultOptions().initialConfig
+
+	spec := &configSpec{}
+	if path != "" {
+		fileSpec, err := readConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		spec = fileSpec
+	}
+
+	envSpec, err := specFromEnv(os.Environ())
+	if err != nil {
+		return nil, fmt.Errorf("slogx: environment: %w", err)
+	}
+	if err := spec.merge(envSpec).apply(cfg); err != nil {
+		return nil, fmt.Errorf("slogx: %s: %w", configSource(path), err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// configSource names where a configuration comes from in error messages.
+func configSource(path string) string {
+	if path == "" {
+		return "environment"
+	}
+	return path + " and environment"
+}
+
+// WithConfig starts the logger with a copy of cfg (e.g. from LoadConfig). It replaces
+// the configuration built by the options before it; options after it modify the copy.
+func WithConfig(cfg *Config) Option {
+	return func(o *options) {
+		if cfg != nil {
+			o.initialConfig = cfg.Clone()
+		}
+	}
+}
+
+// WatchConfig applies the file at path (see LoadConfig) and re-applies it whenever
+// its contents change, until ctx is done. The file is polled every interval
+// (2s if interval is zero). SLOGX_* environment variables keep precedence over it.
+//
+// A reload applies only the settings whose value in the file or the environment
+// changed since the previous load; a setting removed from them returns to the value
+// the logger had when WatchConfig was called. Other settings keep the values they
+// were given meanwhile, e.g. by SetLevel or AdminHandler.
+//
+// A file that cannot be read or parsed, or that yields an invalid configuration, is
+// not applied: the logger keeps the last good configuration and the error is logged.
+// WatchConfig returns an error only if the first load fails.
+func (l *Logger) WatchConfig(ctx context.Context, path string, interval time.Duration) error {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	base := l.cfgPtr.Load()
+	last, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("slogx: read config: %w", err)
+	}
+	loaded, err := l.applyConfigFile(base, &configSpec{}, path, last)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var lastErr string
+		report := func(err error) {
+			// Report each failure once instead of on every poll
+			if err.Error() != lastErr {
+				lastErr = err.Error()
+				l.Error("logger config reload failed", "path", path, "error", err)
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				report(err)
+				continue
+			}
+			if bytes.Equal(data, last) {
+				continue
+			}
+
+			last = data
+			spec, err := l.applyConfigFile(base, loaded, path, data)
+			if err != nil {
+				report(err)
+				continue
+			}
+			loaded = spec
+			lastErr = ""
+			if warnings := l.Config().Warnings(); len(warnings) > 0 {
+				l.Warn("logger config reloaded", "path", path, "warnings", warnings)
+			} else {
+				l.Info("logger config reloaded", "path", path)
+			}
+		}
+	}()
+	return nil
+}
+
+// applyConfigFile applies, in one update, the changes between prev, the spec of the
+// previous load, and the spec built from the contents of the file at path and the
+// environment, both on top of base. It returns the new spec; nothing is applied if
+// any step fails.
+func (l *Logger) applyConfigFile(base *Config, prev *configSpec, path string, data []byte) (*configSpec, error) {
+	fileSpec, err := parseConfigFile(data, filepath.Ext(path))
+	if err != nil {
+		return nil, fmt.Errorf("slogx: %s: %w", path, err)
+	}
+	envSpec, err := specFromEnv(os.Environ())
+	if err != nil {
+		return nil, fmt.Errorf("slogx: environment: %w", err)
+	}
+
+	spec := fileSpec.merge(envSpec)
+	next := base.Clone()
+	if err := spec.apply(next); err != nil {
+		return nil, fmt.Errorf("slogx: %s: %w", configSource(path), err)
+	}
+	// prev applied without error when it was loaded
+	prevCfg := base.Clone()
+	_ = prev.apply(prevCfg)
+
+	err = l.TryUpdateConfig(func(c *Config) { applySpecChanges(c, prevCfg, next) })
+	if err != nil {
+		return nil, fmt.Errorf("slogx: %s: %w", path, err)
+	}
+	return spec, nil
+}
+
+func readConfigFile(path string) (*configSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("slogx: read config: %w", err)
+	}
+	spec, err := parseConfigFile(data, filepath.Ext(path))
+	if err != nil {
+		return nil, fmt.Errorf("slogx: %s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// parseConfigFile parses JSON files (by extension) or KEY=VALUE files.
+func parseConfigFile(data []byte, ext string) (*configSpec, error) {
+	if strings.EqualFold(ext, ".json") {
+		spec := &configSpec{}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(spec); err != nil {
+			return nil, err
+		}
+		return spec, nil
+	}
+
+	var vars []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", n)
+		}
+		vars = append(vars, strings.TrimSpace(key)+"="+strings.Trim(strings.TrimSpace(value), `"'`))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return specFromEnv(vars)
+}
+
+// specFromEnv builds a spec from KEY=VALUE pairs, ignoring keys without EnvPrefix.
+func specFromEnv(vars []string) (*configSpec, error) {
+	spec := &configSpec{}
+	for _, kv := range vars {
+		key, value, _ := strings.Cut(kv, "=")
+		name, ok := strings.CutPrefix(key, EnvPrefix)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "LEVEL":
+			spec.Level = &value
+		case name == "FORMAT":
+			var f Format
+			if err := f.UnmarshalText([]byte(value)); err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			spec.Format = &f
+		case strings.HasPrefix(name, "MASK_"):
+			var t MaskType
+			if err := t.UnmarshalText([]byte(value)); err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			if spec.MaskKeys == nil {
+				spec.MaskKeys = make(map[string]MaskType)
+			}
+			spec.MaskKeys[strings.TrimPrefix(name, "MASK_")] = t
+		case name == "REMOVE":
+			keys := splitList(value)
+			spec.RemoveKeys = &keys
+		case name == "CONTEXT_KEYS":
+			keys := splitList(value)
+			spec.ContextKeys = &keys
+		case name == "LOGGER_LEVELS":
+			spec.LoggerLevels = make(map[string]string)
+			for _, pair := range splitList(value) {
+				logger, lvl, ok := strings.Cut(pair, "=")
+				if !ok {
+					return nil, fmt.Errorf("%s: expected name=level, got %q", key, pair)
+				}
+				spec.LoggerLevels[strings.TrimSpace(logger)] = strings.TrimSpace(lvl)
+			}
+		default:
+			return nil, fmt.Errorf("unknown variable %s", key)
+		}
+	}
+	return spec, nil
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(s string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
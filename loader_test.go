@@ -0,0 +1,199 @@
+package slogx
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.json")
+	require.NoError(
+		t, os.WriteFile(
+			path, []byte(`{
+				"level": "debug",
+				"format": "logfmt",
+				"mask_keys": {"email": "email", "card": "card"},
+				"remove_keys": ["password"],
+				"level_names": {"INFO+2": "NOTICE"},
+				"logger_levels": {"db": "notice"}
+			}`), 0o644,
+		),
+	)
+	t.Setenv("SLOGX_LEVEL", "warn")
+	t.Setenv("SLOGX_MASK_phone", "phone")
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, cfg.Level, "the environment takes precedence")
+	assert.Equal(t, FormatLogfmt, cfg.Format)
+	assert.Equal(
+		t, MaskMap{"email": MaskEmail, "card": MaskCard, "phone": MaskPhone}, cfg.MaskKeys,
+		"mask keys are merged key by key",
+	)
+	assert.Equal(t, RemoveMap{"password": {}}, cfg.RemoveKeys)
+	assert.Equal(t, "NOTICE", cfg.LevelNames[slog.LevelInfo+2])
+	assert.Equal(t, "TRACE", cfg.LevelNames[LevelTrace], "level names are added to the defaults")
+	assert.Equal(t, slog.LevelInfo+2, cfg.LoggerLevels["db"])
+	assert.NotNil(t, cfg.Output)
+	assert.NotNil(t, cfg.Masker)
+}
+
+func TestLoadConfig_EnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.env")
+	require.NoError(
+		t, os.WriteFile(
+			path, []byte(`
+# logging
+SLOGX_LEVEL=trace
+SLOGX_FORMAT="json"
+SLOGX_REMOVE=password, token
+SLOGX_CONTEXT_KEYS=request_id
+SLOGX_LOGGER_LEVELS=db=debug,http.client=warn
+OTHER_VAR=ignored
+`), 0o644,
+		),
+	)
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, LevelTrace, cfg.Level)
+	assert.Equal(t, FormatJSON, cfg.Format)
+	assert.Equal(t, RemoveMap{"password": {}, "token": {}}, cfg.RemoveKeys)
+	assert.Equal(t, []string{"request_id"}, cfg.ContextKeys)
+	assert.Equal(t, map[string]slog.Level{"db": slog.LevelDebug, "http.client": slog.LevelWarn}, cfg.LoggerLevels)
+
+	l := New(WithConfig(cfg), WithLevel(slog.LevelInfo))
+	assert.Equal(t, slog.LevelInfo, l.cfgPtr.Load().Level, "options after WithConfig modify the copy")
+	assert.Equal(t, LevelTrace, cfg.Level)
+}
+
+func TestLoadConfig_Errors(t *testing.T) {
+	dir := t.TempDir()
+	for name, tc := range map[string]struct {
+		file, content string
+	}{
+		"unknown variable": {"a.env", "SLOGX_LEVLE=debug"},
+		"bad line":         {"b.env", "SLOGX_LEVEL"},
+		"bad level":        {"c.env", "SLOGX_LEVEL=verbose"},
+		"bad format":       {"d.json", `{"format":"yaml"}`},
+		"bad mask":         {"e.json", `{"mask_keys":{"a":"rot13"}}`},
+		"unknown field":    {"f.json", `{"levels":"debug"}`},
+		"bad logger level": {"g.env", "SLOGX_LOGGER_LEVELS=db"},
+		"empty level name": {"h.json", `{"level_names":{"INFO+2":" "}}`},
+		"mask and remove":  {"i.env", "SLOGX_MASK_token=secret\nSLOGX_REMOVE=token"},
+	} {
+		t.Run(
+			name, func(t *testing.T) {
+				path := filepath.Join(dir, tc.file)
+				require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o644))
+				_, err := LoadConfig(path)
+				assert.Error(t, err)
+			},
+		)
+	}
+
+	_, err := LoadConfig(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestLoadConfig_EnvMerge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.json")
+	require.NoError(
+		t, os.WriteFile(
+			path, []byte(`{
+				"remove_keys": ["password"],
+				"logger_levels": {"db": "debug", "http": "warn"}
+			}`), 0o644,
+		),
+	)
+	t.Setenv("SLOGX_REMOVE", "token")
+	t.Setenv("SLOGX_LOGGER_LEVELS", "db=error")
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, RemoveMap{"password": {}, "token": {}}, cfg.RemoveKeys)
+	assert.Equal(t, map[string]slog.Level{"db": slog.LevelError, "http": slog.LevelWarn}, cfg.LoggerLevels)
+}
+
+func TestLogger_WatchConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"level":"warn"}`), 0o644))
+
+	out := &syncBuffer{}
+	l := New(WithOutput(out), WithLevel(slog.LevelInfo))
+	level := func() slog.Level { return l.cfgPtr.Load().Level }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, l.WatchConfig(ctx, path, 10*time.Millisecond))
+	assert.Equal(t, slog.LevelWarn, level())
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"level":"debug","mask_keys":{"email":"email"}}`), 0o644))
+	require.Eventually(t, func() bool { return level() == slog.LevelDebug }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, MaskEmail, l.cfgPtr.Load().MaskKeys["email"])
+
+	// A broken file keeps the last good configuration
+	good := l.cfgPtr.Load()
+	require.NoError(t, os.WriteFile(path, []byte(`{"level":"debug","mask_keys":{"email":"rot13"}}`), 0o644))
+	require.Eventually(
+		t, func() bool { return strings.Contains(out.String(), "logger config reload failed") },
+		2*time.Second, 5*time.Millisecond,
+	)
+	assert.Same(t, good, l.cfgPtr.Load())
+	assert.Equal(t, 1, strings.Count(out.String(), "reload failed"), "a failure is reported once")
+
+	// Keys removed from the file return to the configuration the watch started from
+	require.NoError(t, os.WriteFile(path, []byte(`{"level":"error"}`), 0o644))
+	require.Eventually(t, func() bool { return level() == slog.LevelError }, 2*time.Second, 5*time.Millisecond)
+	assert.NotContains(t, l.cfgPtr.Load().MaskKeys, "email")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{}`), 0o644))
+	require.Eventually(t, func() bool { return level() == slog.LevelInfo }, 2*time.Second, 5*time.Millisecond)
+
+	assert.Error(t, l.WatchConfig(ctx, filepath.Join(t.TempDir(), "missing.json"), 0))
+}
+
+func TestLogger_WatchConfigKeepsRuntimeChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"level":"warn","remove_keys":["token"]}`), 0o644))
+
+	l := New(WithOutput(io.Discard), WithLevel(slog.LevelInfo))
+	cfg := func() *Config { return l.cfgPtr.Load() }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, l.WatchConfig(ctx, path, 10*time.Millisecond))
+	assert.Equal(t, slog.LevelWarn, cfg().Level)
+
+	l.SetLevel(slog.LevelDebug)
+	l.UpdateConfig(func(c *Config) { c.MaskKeys["phone"] = MaskPhone })
+
+	// Settings the edit does not touch keep their runtime values
+	require.NoError(
+		t, os.WriteFile(path, []byte(`{"level":"warn","remove_keys":["token"],"mask_keys":{"email":"email"}}`), 0o644),
+	)
+	require.Eventually(t, func() bool { return cfg().MaskKeys["email"] == MaskEmail }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, slog.LevelDebug, cfg().Level)
+	assert.Equal(t, MaskPhone, cfg().MaskKeys["phone"])
+	assert.Contains(t, cfg().RemoveKeys, "token")
+
+	// A setting removed from the file still returns to its value at the start of the watch
+	require.NoError(t, os.WriteFile(path, []byte(`{"level":"warn","mask_keys":{"email":"email"}}`), 0o644))
+	require.Eventually(
+		t, func() bool { _, ok := cfg().RemoveKeys["token"]; return !ok }, 2*time.Second, 5*time.Millisecond,
+	)
+	assert.Equal(t, slog.LevelDebug, cfg().Level)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"mask_keys":{"email":"email"}}`), 0o644))
+	require.Eventually(t, func() bool { return cfg().Level == slog.LevelInfo }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, MaskPhone, cfg().MaskKeys["phone"])
+}
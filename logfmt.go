@@ -0,0 +1,130 @@
+package slogx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// newLogfmtHandler returns the handler behind FormatLogfmt.
+func newLogfmtHandler(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	return newEncodeHandler(w, opts, encodeLogfmt)
+}
+
+// encodeLogfmt renders a record as strict logfmt:
+//
+//	time=2026-10-16T12:00:00.000Z level=INFO msg="user login" req.id=42 req.path="/a b"
+//
+// Group members are flattened into dotted keys, keys are restricted to printable
+// characters other than '=' and '"' (anything else becomes '_'), and values are
+// quoted and escaped whenever they are empty or contain spaces, '=', '"' or
+// non-printable characters.
+func encodeLogfmt(buf []byte, e *entry) []byte {
+	first := true
+	appendPair := func(key string, v slog.Value) {
+		if !first {
+			buf = append(buf, ' ')
+		}
+		first = false
+		buf = appendLogfmtKey(buf, key)
+		buf = append(buf, '=')
+		buf = appendLogfmtValue(buf, v)
+	}
+
+	var walk func(prefix string, a slog.Attr)
+	walk = func(prefix string, a slog.Attr) {
+		if a.Value.Kind() == slog.KindGroup {
+			for _, m := range a.Value.Group() {
+				walk(prefix+a.Key+pathSep, m)
+			}
+			return
+		}
+		appendPair(prefix+a.Key, a.Value)
+	}
+
+	for _, a := range []slog.Attr{e.time, e.lvl, e.msg} {
+		if a.Key != "" {
+			walk("", a)
+		}
+	}
+	for _, a := range e.attrs {
+		walk("", a)
+	}
+	return append(buf, '\n')
+}
+
+func appendLogfmtKey(buf []byte, key string) []byte {
+	if key == "" {
+		return append(buf, '_')
+	}
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f || r == utf8.RuneError {
+			r = '_'
+		}
+		buf = utf8.AppendRune(buf, r)
+	}
+	return buf
+}
+
+func appendLogfmtValue(buf []byte, v slog.Value) []byte {
+	var s string
+	switch v.Kind() {
+	case slog.KindString:
+		s = v.String()
+	case slog.KindInt64:
+		return strconv.AppendInt(buf, v.Int64(), 10)
+	case slog.KindUint64:
+		return strconv.AppendUint(buf, v.Uint64(), 10)
+	case slog.KindFloat64:
+		return strconv.AppendFloat(buf, v.Float64(), 'g', -1, 64)
+	case slog.KindBool:
+		return strconv.AppendBool(buf, v.Bool())
+	case slog.KindDuration:
+		s = v.Duration().String()
+	case slog.KindTime:
+		return v.Time().AppendFormat(buf, time.RFC3339Nano)
+	default:
+		s = anyString(v.Any())
+	}
+
+	if !needsLogfmtQuoting(s) {
+		return append(buf, s...)
+	}
+	return strconv.AppendQuote(buf, s)
+}
+
+func needsLogfmtQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f || r == utf8.RuneError {
+			return true
+		}
+	}
+	return false
+}
+
+// anyString renders an arbitrary value as text: errors and Stringers by their
+// message, types with a JSON encoding as JSON, everything else with %+v.
+func anyString(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return "<nil>"
+	case error:
+		return x.Error()
+	case fmt.Stringer:
+		return x.String()
+	case []byte:
+		return string(x)
+	case json.Marshaler:
+		if b, err := x.MarshalJSON(); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprintf("%+v", v)
+}
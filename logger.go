@@ -2,16 +2,122 @@ package slogx
 
 import (
 	"context"
+	"errors"
+	"io"
 	"log/slog"
 	"os"
+	"reflect"
+	"sync"
 	"sync/atomic"
+	"time"
 )
 
+// fatalFlushTimeout bounds how long FatalContext waits for buffered outputs before exiting.
+const fatalFlushTimeout = 5 * time.Second
+
 // Logger is a wrapper around slog.Logger that supports atomic configuration updates.
 // It allows changing log levels, formats, and sanitization rules at runtime without restarts.
 type Logger struct {
 	*slog.Logger
 	cfgPtr *atomic.Pointer[Config]
+	state  *loggerState
+}
+
+// loggerState is shared by a Logger, every logger derived from it and their handlers.
+type loggerState struct {
+	// cfgMu serializes the start and end of temporary overrides and the updates of the
+	// base configuration while they are active. Other updates are lock-free (see Logger.update).
+	cfgMu sync.Mutex
+	// overridden is set while temporary overrides are active or being started; updates
+	// then go through cfgMu and change the base configuration.
+	overridden atomic.Bool
+	// base is the configuration without temporary overrides; nil when none is active.
+	base *Config
+	// overrides are the active temporary overrides in the order they were started.
+	overrides []*Override
+	// listenersMu guards listeners, which are notified of every published configuration.
+	listenersMu sync.Mutex
+	listeners   []*listener
+	// notify delivers the published configurations to the listeners.
+	notify notifier
+	// root writes the records of the logger itself, such as "logger reconfigured".
+	root *slog.Logger
+	// throttle applies Config.Sampling and Config.RateLimit across all derived loggers.
+	throttle *throttle
+	// dedup holds the open Config.Dedup windows of all derived loggers.
+	dedup *dedup
+	// flight is the flight recorder buffer of records logged without a scope.
+	flight flightBuffer
+	// writeLocks holds a *sync.Mutex per output, keyed by its address (see writerKey).
+	// Handler chains built from different configurations (or rebuilt concurrently) have
+	// their own locks, so writes to a shared output are serialized here.
+	writeLocks sync.Map
+}
+
+// writerKey returns the key of w in writeLocks: the address of a pointer writer, which
+// is always hashable, unlike the writer itself (e.g. a struct wrapping a func writer).
+// It reports false for writers that are not pointers.
+func writerKey(w io.Writer) (uintptr, bool) {
+	v := reflect.ValueOf(w)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return 0, false
+	}
+	return v.Pointer(), true
+}
+
+// lockedWriter returns w wrapped so that all writes through the state are serialized.
+// Writers that are not pointers are returned as is.
+func (s *loggerState) lockedWriter(w io.Writer) io.Writer {
+	if s == nil || w == nil {
+		return w
+	}
+	key, ok := writerKey(w)
+	if !ok {
+		return w
+	}
+	mu, _ := s.writeLocks.LoadOrStore(key, &sync.Mutex{})
+	return &lockedWriter{w: w, mu: mu.(*sync.Mutex)}
+}
+
+// releaseWriteLocks drops the locks of the outputs that cfg no longer uses, such as
+// the output replaced by SwapOutput or a removed sink. Chains still writing to them
+// keep the lock they were built with.
+func (s *loggerState) releaseWriteLocks(cfg *Config) {
+	used := make(map[uintptr]struct{}, len(cfg.Sinks)+1)
+	writers := []io.Writer{cfg.Output}
+	for _, sink := range cfg.Sinks {
+		writers = append(writers, sink.Output)
+	}
+	for _, w := range writers {
+		if key, ok := writerKey(w); ok {
+			used[key] = struct{}{}
+		}
+	}
+	s.writeLocks.Range(
+		func(key, _ any) bool {
+			if _, ok := used[key.(uintptr)]; !ok {
+				s.writeLocks.Delete(key)
+			}
+			return true
+		},
+	)
+}
+
+// lockedWriter serializes writes to w with a mutex shared by all handler chains.
+type lockedWriter struct {
+	w  io.Writer
+	mu *sync.Mutex
+}
+
+func (lw *lockedWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	return lw.w.Write(p)
+}
+
+// Unwrap returns the underlying writer, e.g. for terminal detection.
+func (lw *lockedWriter) Unwrap() io.Writer {
+	return lw.w
 }
 
 // New creates a new Logger instance with the provided options.
@@ -26,16 +132,29 @@ func New(opts ...Option) *Logger {
 
 	// Initialize atomic pointer for thread-safe configuration management
 	ptr := &atomic.Pointer[Config]{}
+
+	// Every logger counts its own log calls in flight (see SwapOutput)
+	o.initialConfig.gen = newOutputGen()
 	ptr.Store(o.initialConfig)
 
+	state := &loggerState{}
+	state.notify.next = o.initialConfig.Version + 1
+
 	// Create a dynamic handler that reacts to config changes in real-time
 	handler := &DynamicHandler{
-		cfg: ptr,
+		cfg:   ptr,
+		state: state,
 	}
 
+	root := slog.New(handler)
+	state.root = root
+	state.throttle = &throttle{}
+	state.dedup = newDedup()
+
 	return &Logger{
-		Logger: slog.New(handler),
+		Logger: root,
 		cfgPtr: ptr,
+		state:  state,
 	}
 }
 
@@ -44,6 +163,7 @@ func (l *Logger) With(args ...any) *Logger {
 	return &Logger{
 		Logger: l.Logger.With(args...),
 		cfgPtr: l.cfgPtr,
+		state:  l.state,
 	}
 }
 
@@ -52,16 +172,126 @@ func (l *Logger) WithGroup(name string) *Logger {
 	return &Logger{
 		Logger: l.Logger.WithGroup(name),
 		cfgPtr: l.cfgPtr,
+		state:  l.state,
+	}
+}
+
+// Named returns a child logger with a hierarchical name: log.Named("http").Named("client")
+// is named "http.client". The name is logged under LoggerKey and selects the level
+// from Config.LoggerLevels, falling back to the closest parent name and then Config.Level.
+func (l *Logger) Named(name string) *Logger {
+	if name == "" {
+		return l
+	}
+	h, ok := l.Handler().(*DynamicHandler)
+	if !ok {
+		return l.With(LoggerKey, name)
+	}
+	return &Logger{
+		Logger: slog.New(h.withName(name)),
+		cfgPtr: l.cfgPtr,
+		state:  l.state,
 	}
 }
 
+// Config returns the current configuration, including active temporary overrides.
+// The returned value is shared and must not be modified; use UpdateConfig instead.
+func (l *Logger) Config() *Config {
+	return l.cfgPtr.Load()
+}
+
+// ErrConfigConflict is returned by UpdateConfigIf when the configuration was changed
+// after the version the caller based its update on.
+var ErrConfigConflict = errors.New("slogx: config was changed concurrently")
+
 // UpdateConfig allows thread-safe, atomic updates to the logger's configuration.
-// It uses a copy-on-write strategy by cloning the current config and applying the provided function.
+// It uses a copy-on-write strategy by cloning the current config and applying the provided function,
+// then publishes the result with a compare-and-swap. If another update was published in
+// the meantime, fn is applied again to the new configuration, so no update is lost;
+// fn may therefore be called more than once and must only modify the Config it is given.
+// While temporary overrides are active (see UpdateConfigFor), fn is applied to the base configuration.
+// The result is not validated; use TryUpdateConfig to reject invalid configurations.
 func (l *Logger) UpdateConfig(fn func(*Config)) {
-	oldCfg := l.cfgPtr.Load()
-	newCfg := oldCfg.Clone()
-	fn(newCfg)
-	l.cfgPtr.Store(newCfg)
+	_ = l.update(nil, false, fn)
+}
+
+// TryUpdateConfig applies fn like UpdateConfig, but validates the result (see Config.Validate)
+// and returns the error instead of publishing an invalid configuration.
+func (l *Logger) TryUpdateConfig(fn func(*Config)) error {
+	return l.update(nil, true, fn)
+}
+
+// UpdateConfigIf applies fn like TryUpdateConfig, but only if the current configuration
+// still has the given Version; otherwise it returns ErrConfigConflict and changes nothing.
+// It provides optimistic concurrency to callers that show the configuration to a user
+// and apply the user's edits later, such as admin APIs.
+func (l *Logger) UpdateConfigIf(version uint64, fn func(*Config)) error {
+	return l.update(&version, true, fn)
+}
+
+// update is the compare-and-swap retry loop behind UpdateConfig: it applies fn to a copy
+// of the current (or, while overrides are active, the base) configuration and publishes
+// the result unless another update was published first, in which case it starts over.
+// If version is not nil, the update is refused when the published configuration has a
+// different version. If validate is set, it is refused when the result is invalid.
+func (l *Logger) update(version *uint64, validate bool, fn func(*Config)) error {
+	for {
+		cur := l.cfgPtr.Load()
+		if version != nil && cur.Version != *version {
+			return ErrConfigConflict
+		}
+
+		if l.state != nil && l.state.overridden.Load() {
+			done, err := l.updateBase(cur, validate, fn)
+			if err != nil {
+				return err
+			}
+			if done {
+				l.deliver()
+				return nil
+			}
+			continue
+		}
+
+		newCfg := cur.Clone()
+		fn(newCfg)
+		if validate {
+			if err := newCfg.Validate(); err != nil {
+				return err
+			}
+		}
+		if l.publish(cur, newCfg) {
+			l.deliver()
+			return nil
+		}
+	}
+}
+
+// updateBase applies fn to the base configuration while temporary overrides are active.
+// It reports false when cur is no longer current or the overrides have ended, so that
+// update starts over.
+func (l *Logger) updateBase(cur *Config, validate bool, fn func(*Config)) (bool, error) {
+	st := l.state
+	st.cfgMu.Lock()
+	defer st.cfgMu.Unlock()
+
+	if st.base == nil || l.cfgPtr.Load() != cur {
+		return false, nil
+	}
+
+	base := st.base.Clone()
+	fn(base)
+	newCfg := l.withOverrides(base)
+	if validate {
+		if err := newCfg.Validate(); err != nil {
+			return false, err
+		}
+	}
+	if !l.publish(cur, newCfg) {
+		return false, nil
+	}
+	st.base = base
+	return true, nil
 }
 
 // SetLevel is a convenience method to quickly update the logging threshold.
@@ -79,11 +309,201 @@ func (l *Logger) TraceContext(ctx context.Context, msg string, args ...any) {
 }
 
 // FatalContext logs a message at the LevelFatal level and immediately terminates the process with exit code 1.
+// Records kept by the flight recorder are written first, and buffered outputs are
+// flushed (for at most fatalFlushTimeout) before exiting.
 func (l *Logger) FatalContext(ctx context.Context, msg string, args ...any) {
+	// Write what the flight recorder kept, whatever its trigger level
+	l.dumpFlightRecorders(ctx)
 	l.Log(ctx, LevelFatal, msg, args...)
+
+	flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), fatalFlushTimeout)
+	_ = l.Flush(flushCtx)
+	cancel()
+
 	os.Exit(1)
 }
 
+// Flush writes pending dedup follow-ups and suppression summaries (see Config.Dedup
+// and Config.SuppressionSummary) and waits
+// until all buffered outputs (see Flusher) of the current configuration, including
+// every sink, have written their records, or until ctx is done.
+func (l *Logger) Flush(ctx context.Context) error {
+	// Pending dedup follow-ups and suppression summaries are written first so that
+	// they are flushed too
+	if l.state != nil {
+		l.state.dedup.flush()
+		l.state.throttle.summarize()
+	}
+
+	var errs []error
+	for _, w := range l.outputs() {
+		if f, ok := w.(Flusher); ok {
+			if err := f.Flush(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close flushes all buffered outputs and closes the ones managed by slogx
+// (outputs implementing both Flusher and io.Closer, such as AsyncWriter).
+// Plain writers like os.Stdout or a caller-opened file are left open.
+func (l *Logger) Close() error {
+	errs := []error{l.Flush(context.Background())}
+	for _, w := range l.outputs() {
+		if _, ok := w.(Flusher); !ok {
+			continue
+		}
+		if c, ok := w.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SwapOutput replaces Config.Output with w without losing records in flight.
+// After the new config is published it waits until every log call that may still
+// write to the previous output has returned, then flushes the previous output and,
+// if it is managed by slogx (Flusher and io.Closer, e.g. RotatingFile or AsyncWriter),
+// closes it. ctx bounds the wait.
+//
+// Only Config.Output is replaced: when Config.Sinks are set, records keep going to
+// the sinks, whose outputs are changed with UpdateConfig.
+func (l *Logger) SwapOutput(ctx context.Context, w io.Writer) error {
+	var prev io.Writer
+	var prevGen *outputGen
+	l.UpdateConfig(
+		func(c *Config) {
+			prev, prevGen = c.Output, c.gen
+			c.Output = w
+			c.gen = newOutputGen()
+		},
+	)
+
+	if err := prevGen.drain(ctx); err != nil {
+		return err
+	}
+	if prev == nil || sameWriter(prev, w) {
+		return nil
+	}
+
+	f, ok := prev.(Flusher)
+	if !ok {
+		return nil
+	}
+	if err := f.Flush(ctx); err != nil {
+		return err
+	}
+	if c, ok := prev.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// genRetired is set in outputGen.n once the generation has been replaced.
+const genRetired = 1 << 62
+
+// outputGen counts the log calls in flight on the configurations published between
+// two SwapOutput calls, so that SwapOutput can wait for the calls still writing to
+// the previous output without a lock shared by every log call.
+type outputGen struct {
+	// n is the number of calls in flight, with genRetired set once retired.
+	n    atomic.Int64
+	done chan struct{}
+	once sync.Once
+}
+
+func newOutputGen() *outputGen {
+	return &outputGen{done: make(chan struct{})}
+}
+
+// acquire registers a log call. It fails once the generation is retired: the call
+// must then use the current configuration. A nil generation is not tracked.
+func (g *outputGen) acquire() bool {
+	if g == nil {
+		return true
+	}
+	if g.n.Add(1)&genRetired != 0 {
+		g.release()
+		return false
+	}
+	return true
+}
+
+// release ends a log call registered with acquire.
+func (g *outputGen) release() {
+	if g != nil && g.n.Add(-1) == genRetired {
+		g.once.Do(func() { close(g.done) })
+	}
+}
+
+// drain retires the generation and waits until its calls in flight have returned,
+// or until ctx is done.
+func (g *outputGen) drain(ctx context.Context) error {
+	if g == nil {
+		return nil
+	}
+	if g.n.Or(genRetired) == 0 {
+		g.once.Do(func() { close(g.done) })
+	}
+
+	select {
+	case <-g.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sameWriter reports whether a and b are the same writer. Writers that cannot be
+// compared, such as func based writers or structs holding one, are never the same.
+func sameWriter(a, b io.Writer) (same bool) {
+	if a == nil || b == nil {
+		return a == b
+	}
+	t := reflect.TypeOf(a)
+	if t != reflect.TypeOf(b) || !t.Comparable() {
+		return false
+	}
+	// A comparable type may still hold an incomparable value in an interface field
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+	return a == b
+}
+
+// outputs returns the distinct writers of the current configuration.
+func (l *Logger) outputs() []io.Writer {
+	if l.cfgPtr == nil {
+		return nil
+	}
+	cfg := l.cfgPtr.Load()
+
+	var out []io.Writer
+	add := func(w io.Writer) {
+		if w == nil {
+			return
+		}
+		for _, seen := range out {
+			if sameWriter(seen, w) {
+				return
+			}
+		}
+		out = append(out, w)
+	}
+
+	if len(cfg.Sinks) == 0 {
+		add(cfg.Output)
+	}
+	for _, s := range cfg.Sinks {
+		add(s.Output)
+	}
+	return out
+}
+
 // SetupDefault initializes a new Logger and sets it as the global default logger for the slog package.
 func SetupDefault(opts ...Option) {
 	l := New(opts...)
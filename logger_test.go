@@ -2,11 +2,17 @@ package slogx
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"log/slog"
+	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLogger_UpdateConfig(t *testing.T) {
@@ -53,3 +59,173 @@ func TestLogger_FormatSwitch(t *testing.T) {
 	assert.Contains(t, buf.String(), `"level":"INFO"`)
 	assert.True(t, json.Valid(buf.Bytes()))
 }
+
+func TestLogger_Named(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(
+		WithOutput(buf),
+		WithFormat(FormatJSON),
+		WithLevel(slog.LevelInfo),
+		WithLoggerLevel("db", slog.LevelDebug),
+	)
+
+	db := l.Named("db")
+	client := l.Named("http").Named("client").With("peer", "billing")
+
+	db.Debug("query")
+	var m map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	assert.Equal(t, "db", m["logger"])
+	buf.Reset()
+
+	client.Debug("invisible")
+	l.Debug("invisible")
+	assert.Empty(t, buf.String(), "unconfigured names use Config.Level")
+
+	client.Info("request")
+	m = nil
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	assert.Equal(t, "http.client", m["logger"])
+	assert.Equal(t, "billing", m["peer"])
+	buf.Reset()
+
+	// Parent names apply to children; a child entry overrides its parent
+	l.UpdateConfig(
+		func(c *Config) {
+			c.LoggerLevels["http"] = slog.LevelWarn
+			c.LoggerLevels["db"] = slog.LevelError
+		},
+	)
+	client.Info("invisible")
+	db.Warn("invisible")
+	assert.Empty(t, buf.String())
+
+	l.UpdateConfig(func(c *Config) { c.LoggerLevels["http.client"] = slog.LevelDebug })
+	client.Debug("visible")
+	assert.Contains(t, buf.String(), "visible")
+
+	assert.Same(t, l, l.Named(""))
+}
+
+func TestResolveLoggerLevel(t *testing.T) {
+	levels := map[string]slog.Level{
+		"http":        slog.LevelWarn,
+		"http.client": slog.LevelDebug,
+	}
+	assert.Equal(t, slog.LevelDebug, resolveLoggerLevel("http.client", levels, slog.LevelInfo))
+	assert.Equal(t, slog.LevelDebug, resolveLoggerLevel("http.client.retry", levels, slog.LevelInfo))
+	assert.Equal(t, slog.LevelWarn, resolveLoggerLevel("http.server", levels, slog.LevelInfo))
+	assert.Equal(t, slog.LevelInfo, resolveLoggerLevel("httpx", levels, slog.LevelInfo))
+	assert.Equal(t, slog.LevelInfo, resolveLoggerLevel("", levels, slog.LevelInfo))
+}
+
+func TestLogger_UpdateConfigConcurrent(t *testing.T) {
+	// A plain bytes.Buffer: handler chains rebuilt for each config must still not write concurrently
+	l := New(WithOutput(&bytes.Buffer{}))
+	start := l.cfgPtr.Load().Version
+
+	const writers, updates = 8, 50
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < updates; i++ {
+				key := fmt.Sprintf("w%d_%d", w, i)
+				l.UpdateConfig(func(c *Config) { c.MaskKeys[key] = MaskDefault })
+				l.Info("during update", "i", i)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	cfg := l.cfgPtr.Load()
+	assert.Len(t, cfg.MaskKeys, writers*updates, "no update may be lost")
+	assert.Equal(t, start+writers*updates, cfg.Version)
+}
+
+func TestLogger_UpdateConfigRetries(t *testing.T) {
+	l := New(WithOutput(&bytes.Buffer{}))
+	start := l.Config().Version
+
+	// The first attempt loses the race to an update published while fn runs, so the
+	// compare-and-swap fails and fn is applied again on top of that update
+	calls := 0
+	l.UpdateConfig(
+		func(c *Config) {
+			calls++
+			if calls == 1 {
+				l.UpdateConfig(func(c *Config) { c.MaskKeys["inner"] = MaskDefault })
+			}
+			c.MaskKeys["outer"] = MaskSecret
+		},
+	)
+
+	assert.Equal(t, 2, calls)
+	cfg := l.Config()
+	assert.Equal(t, MaskDefault, cfg.MaskKeys["inner"])
+	assert.Equal(t, MaskSecret, cfg.MaskKeys["outer"])
+	assert.Equal(t, start+2, cfg.Version)
+}
+
+func TestLogger_UpdateConfigIf(t *testing.T) {
+	l := New(WithOutput(&bytes.Buffer{}), WithLevel(slog.LevelInfo))
+
+	v := l.Config().Version
+	require.NoError(t, l.UpdateConfigIf(v, func(c *Config) { c.Level = slog.LevelDebug }))
+	assert.Equal(t, v+1, l.Config().Version)
+
+	err := l.UpdateConfigIf(v, func(c *Config) { c.Level = slog.LevelError })
+	assert.ErrorIs(t, err, ErrConfigConflict)
+	assert.Equal(t, slog.LevelDebug, l.Config().Level)
+
+	// Optimistic read-modify-write loops converge without losing increments
+	const workers, increments = 8, 25
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < increments; i++ {
+				for {
+					cur := l.Config()
+					next := cur.LoggerLevels["counter"] + 1
+					if l.UpdateConfigIf(cur.Version, func(c *Config) { c.LoggerLevels["counter"] = next }) == nil {
+						break
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, slog.Level(workers*increments), l.Config().LoggerLevels["counter"])
+}
+
+// wrappedWriter is a comparable type that panics when compared or hashed while it
+// holds a writer that is not, such as a writerFunc.
+type wrappedWriter struct {
+	io.Writer
+}
+
+func TestLogger_WriteLocks(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(WithOutput(wrappedWriter{writerFunc(buf.Write)}))
+	assert.NotPanics(t, func() { l.Info("hello") })
+	assert.Contains(t, buf.String(), "msg=hello")
+
+	locks := func() int {
+		n := 0
+		l.state.writeLocks.Range(func(_, _ any) bool { n++; return true })
+		return n
+	}
+	first, second := &bytes.Buffer{}, &bytes.Buffer{}
+	require.NoError(t, l.SwapOutput(context.Background(), first))
+	l.Info("first")
+	assert.Equal(t, 1, locks())
+
+	require.NoError(t, l.SwapOutput(context.Background(), second))
+	l.Info("second")
+	assert.Equal(t, 1, locks(), "the lock of a retired output is released")
+	_, ok := l.state.writeLocks.Load(reflect.ValueOf(second).Pointer())
+	assert.True(t, ok)
+}
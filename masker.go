@@ -1,8 +1,12 @@
 package slogx
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"strings"
+	"unicode"
 )
 
 // MaskType defines the category of data being masked to apply the appropriate redaction strategy.
@@ -13,14 +17,22 @@ const (
 	MaskDefault MaskType = iota
 	// MaskEmail redacts email addresses while preserving parts of the username and the domain.
 	MaskEmail
-	// MaskPhone redacts phone numbers while preserving the prefix and last few digits.
+	// MaskPhone redacts phone numbers while preserving the prefix, last few digits and formatting.
 	MaskPhone
-	// MaskCard redacts credit card numbers, showing only the first and last four digits.
+	// MaskCard redacts credit card numbers, showing only the first and last four digits
+	// and keeping the original grouping.
 	MaskCard
 	// MaskSecret completely hides the value and replaces it with a [SECRET] tag.
 	MaskSecret
+	// MaskHash pseudonymizes the value with a truncated HMAC-SHA256 under Config.HashKey,
+	// so equal values produce equal tokens (e.g. "k1:5f2b9c0e7a1d4f38").
+	// Without a configured key it behaves like MaskDefault.
+	MaskHash
 )
 
+// hashTokenBytes is the number of HMAC bytes kept in a pseudonym (hex-encoded in output).
+const hashTokenBytes = 8
+
 // Masker is the interface that wraps the basic Mask method.
 // Any custom masking logic should implement this interface.
 type Masker interface {
@@ -28,8 +40,16 @@ type Masker interface {
 }
 
 // DefaultMasker provides a standard implementation of the Masker interface
-// with built-in rules for common sensitive data types.
-type DefaultMasker struct{}
+// with built-in rules for common sensitive data types. Strategies added with
+// RegisterMaskType are applied as well.
+//
+// Phone and card masking is format-preserving: separators and length are kept
+// and only digits are replaced. Phone and Card override how many digits stay
+// visible; nil keeps the defaults (2+3 for phones, 4+4 for cards).
+type DefaultMasker struct {
+	Phone *Visibility
+	Card  *Visibility
+}
 
 // MaskMap associates attribute keys with masking strategies.
 type MaskMap map[string]MaskType
@@ -45,6 +65,11 @@ func NewMaskRules() *MaskRules {
 }
 
 // Add registers a key with a masking strategy.
+//
+// The key may be a dotted group path: "email" matches the key at any depth,
+// "user.email" only matches email inside the user group, "*" matches exactly one
+// path segment ("*.token") and "**" matches any number of groups
+// ("request.**.authorization").
 func (r *MaskRules) Add(key string, mType MaskType) *MaskRules {
 	r.rules[key] = mType
 	return r
@@ -56,21 +81,63 @@ func (r *MaskRules) Keys() MaskMap {
 }
 
 // Mask processes the input value based on the specified MaskType.
-// It converts the value to a string representation before applying redaction rules.
+// Built-in and registered strategies are looked up in the mask registry;
+// unknown types fall back to the generic [MASKED] tag.
 func (m *DefaultMasker) Mask(value any, mType MaskType) any {
-	valStr := fmt.Sprintf("%v", value)
-	switch mType {
-	case MaskEmail:
-		return maskEmail(valStr)
-	case MaskPhone:
-		return maskPhone(valStr)
-	case MaskCard:
-		return maskCard(valStr)
-	case MaskSecret:
-		return "[SECRET]"
-	default:
-		return "[MASKED]"
+	switch {
+	case mType == MaskPhone && m.Phone != nil:
+		return maskPhoneWith(fmt.Sprintf("%v", value), *m.Phone)
+	case mType == MaskCard && m.Card != nil:
+		return maskCardWith(fmt.Sprintf("%v", value), *m.Card)
+	}
+
+	if s := lookupMaskStrategy(mType); s != nil {
+		return s.Mask(value)
+	}
+	return "[MASKED]"
+}
+
+// Pseudonymize returns a deterministic token for value: the hex-encoded, truncated
+// HMAC-SHA256 of its string representation under key, prefixed with keyID when set.
+// The same value and key always produce the same token, which allows correlating
+// records without revealing the value; rotating the key changes every token.
+func Pseudonymize(key []byte, keyID string, value any) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(fmt.Sprintf("%v", value)))
+	token := hex.EncodeToString(mac.Sum(nil)[:hashTokenBytes])
+	if keyID == "" {
+		return token
+	}
+	return keyID + ":" + token
+}
+
+// hashMasker applies MaskHash with the key from the current Config and
+// delegates every other mask type to the configured Masker.
+type hashMasker struct {
+	Masker
+	key   []byte
+	keyID string
+}
+
+// newConfigMasker returns the Masker used by the handler chain built from cfg.
+func newConfigMasker(cfg *Config) Masker {
+	// A nil Masker is rejected by Validate, but UpdateConfig does not validate
+	masker := cfg.Masker
+	if masker == nil {
+		masker = &DefaultMasker{}
+	}
+	if len(cfg.HashKey) == 0 {
+		return masker
+	}
+	return &hashMasker{Masker: masker, key: cfg.HashKey, keyID: cfg.HashKeyID}
+}
+
+// Mask pseudonymizes MaskHash values and forwards everything else.
+func (m *hashMasker) Mask(value any, mType MaskType) any {
+	if mType == MaskHash {
+		return Pseudonymize(m.key, m.keyID, value)
 	}
+	return m.Masker.Mask(value, mType)
 }
 
 // maskEmail redacts an email address (e.g., "antonioh@gmail.com" -> "an***h@gmail.com").
@@ -89,20 +156,102 @@ func maskEmail(s string) string {
 	return user[:2] + "***" + user[len(user)-1:] + "@" + domain
 }
 
-// maskPhone redacts a phone number (e.g., "+7 911 222 3456" -> "+7 9*******456").
+// Visibility controls how many leading and trailing digits stay visible
+// when a phone or card number is masked.
+type Visibility struct {
+	Leading  int
+	Trailing int
+}
+
+var (
+	// defaultPhoneVisibility keeps the country code prefix and the last 3 digits.
+	defaultPhoneVisibility = Visibility{Leading: 2, Trailing: 3}
+	// defaultCardVisibility keeps the first and last 4 digits of a PAN.
+	defaultCardVisibility = Visibility{Leading: 4, Trailing: 4}
+)
+
+const (
+	minPhoneDigits = 7
+	// maxPhoneDigits is the maximum length of an E.164 number.
+	maxPhoneDigits = 15
+	minCardDigits  = 13
+	maxCardDigits  = 19
+)
+
+// maskPhone redacts a phone number (e.g., "+7 911 222 3456" -> "+7 9** *** *456").
 func maskPhone(s string) string {
-	if len(s) < 8 {
-		return "***"
+	return maskPhoneWith(s, defaultPhoneVisibility)
+}
+
+// maskPhoneWith redacts a phone number keeping its layout and the given visible digits.
+// Inputs that do not look like a phone number (7–15 digits) are masked completely.
+func maskPhoneWith(s string, v Visibility) string {
+	if n := countDigits(s); n < minPhoneDigits || n > maxPhoneDigits {
+		return maskDigits(s, 0, 0)
 	}
-	// Keeps the first 4 characters and the last 3
-	return s[:4] + "*******" + s[len(s)-3:]
+	return maskDigits(s, v.Leading, v.Trailing)
 }
 
-// maskCard redacts a credit card number (e.g., "2313 **** **** 4321").
+// maskCard redacts a card number (e.g., "4276 1234 5678 0000" -> "4276 **** **** 0000").
 func maskCard(s string) string {
-	s = strings.ReplaceAll(s, " ", "")
-	if len(s) < 12 {
-		return "**** **** ****"
+	return maskCardWith(s, defaultCardVisibility)
+}
+
+// maskCardWith redacts a card number keeping its layout and the given visible digits.
+// Inputs that are not a 13–19 digit PAN are masked completely.
+func maskCardWith(s string, v Visibility) string {
+	if n := countDigits(s); n < minCardDigits || n > maxCardDigits {
+		return maskDigits(s, 0, 0)
+	}
+	return maskDigits(s, v.Leading, v.Trailing)
+}
+
+// maskDigits keeps the first lead and the last trail digits of s, replaces every
+// other digit with '*' and leaves separators in place, so the length and layout
+// of s are preserved. Anything that is neither a digit nor a separator is masked
+// too, and if the visible part would cover every digit, all of them are masked.
+func maskDigits(s string, lead, trail int) string {
+	runes := []rune(s)
+	total := 0
+	for _, r := range runes {
+		if unicode.IsDigit(r) {
+			total++
+		}
+	}
+	if lead < 0 || trail < 0 || lead+trail >= total {
+		lead, trail = 0, 0
+	}
+
+	seen := 0
+	for i, r := range runes {
+		switch {
+		case unicode.IsDigit(r):
+			if seen >= lead && seen < total-trail {
+				runes[i] = '*'
+			}
+			seen++
+		case !isNumberSeparator(r):
+			runes[i] = '*'
+		}
+	}
+	return string(runes)
+}
+
+func countDigits(s string) int {
+	n := 0
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// isNumberSeparator reports whether r is formatting inside a phone or card number.
+func isNumberSeparator(r rune) bool {
+	switch r {
+	case ' ', '-', '.', '(', ')', '+', '/':
+		return true
 	}
-	return s[:4] + " **** **** " + s[len(s)-4:]
+	return unicode.IsSpace(r)
 }
@@ -1,6 +1,8 @@
 package slogx
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -16,13 +18,59 @@ func TestMaskingFunctions(t *testing.T) {
 
 	t.Run(
 		"Phone", func(t *testing.T) {
-			assert.Equal(t, "+7 9*******456", maskPhone("+7 911 222 3456"))
+			assert.Equal(t, "+7 9** *** *456", maskPhone("+7 911 222 3456"))
+			assert.Equal(t, "55*-***-*567", maskPhone("555-123-4567"))
+			assert.Equal(t, "+14******671", maskPhone("+14155552671"))
+			assert.Equal(t, "**-**", maskPhone("12-34"))
+		},
+	)
+
+	t.Run(
+		"Visibility", func(t *testing.T) {
+			m := &DefaultMasker{Card: &Visibility{Leading: 6, Trailing: 4}, Phone: &Visibility{Trailing: 2}}
+			assert.Equal(t, "427612******0000", m.Mask("4276123456780000", MaskCard))
+			assert.Equal(t, "+* *** *** **56", m.Mask("+7 911 222 3456", MaskPhone))
 		},
 	)
 
 	t.Run(
 		"Card", func(t *testing.T) {
-			assert.Equal(t, "4276 **** **** 0000", maskCard("4276123456780000"))
+			assert.Equal(t, "4276********0000", maskCard("4276123456780000"))
+			assert.Equal(t, "4276 **** **** 0000", maskCard("4276 1234 5678 0000"))
+			assert.Equal(t, "6011-****-****-***-1117", maskCard("6011-0009-9013-942-1117"))
+			assert.Equal(t, "********", maskCard("12345678"))
+			assert.Equal(t, "４２７６********0000", maskCard("４２７６123456780000"))
+		},
+	)
+}
+
+func TestMaskHash(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(
+		WithOutput(buf),
+		WithMaskKey("user_id", MaskHash),
+		WithHashKey("k1", []byte("secret-1")),
+	)
+
+	l.Info("first", "user_id", "u-42")
+	l.Info("second", "user_id", "u-42")
+	token := Pseudonymize([]byte("secret-1"), "k1", "u-42")
+	assert.Equal(t, 2, strings.Count(buf.String(), "user_id="+token))
+	assert.NotContains(t, buf.String(), "u-42")
+	assert.Len(t, token, len("k1:")+2*hashTokenBytes)
+	buf.Reset()
+
+	// Key rotation changes every token and the key-id prefix.
+	l.UpdateConfig(
+		func(c *Config) {
+			c.HashKey = []byte("secret-2")
+			c.HashKeyID = "k2"
 		},
 	)
+	l.Info("rotated", "user_id", "u-42")
+	assert.Contains(t, buf.String(), "user_id=k2:")
+	assert.NotContains(t, buf.String(), token)
+
+	// Without a key the value is still never revealed.
+	assert.Equal(t, "[MASKED]", (&DefaultMasker{}).Mask("u-42", MaskHash))
 }
@@ -4,10 +4,12 @@ import (
 	"io"
 	"log/slog"
 	"os"
-	"strings"
+	"regexp"
+	"time"
 )
 
-// Format defines the output format for the logger (Text or JSON).
+// Format defines the output format for the logger. Formats map to handler
+// constructors in the format registry; custom formats are added with RegisterFormat.
 type Format int
 
 // RemoveMap is a set of attribute keys that should be completely excluded from the logs.
@@ -18,11 +20,25 @@ const (
 	FormatText Format = iota
 	// FormatJSON represents a structured JSON output format.
 	FormatJSON
+	// FormatConsole represents a colorized, column-aligned format for developer terminals.
+	// Colors are disabled automatically when the output is not a TTY or NO_COLOR is set.
+	FormatConsole
+	// FormatLogfmt represents strict logfmt with escaped keys and values and dotted group keys.
+	FormatLogfmt
+	// FormatGELF represents Graylog Extended Log Format 1.1 JSON, one message per line.
+	FormatGELF
+	// FormatCBOR represents a compact binary encoding: one CBOR map per record (RFC 8949),
+	// written as a CBOR sequence (RFC 8742).
+	FormatCBOR
 )
 
 // Config represents the atomic logger configuration state.
 // It includes level management, formatting, and data sanitization rules.
 type Config struct {
+	// Version identifies the published configuration. It is incremented by every
+	// update (see Logger.UpdateConfigIf); values set by callers are ignored.
+	Version uint64
+
 	Level       slog.Level
 	Format      Format
 	Output      io.Writer
@@ -31,6 +47,55 @@ type Config struct {
 	LevelNames  LevelNames
 	Masker      Masker
 	ContextKeys []string
+
+	// LoggerLevels overrides Level for named loggers (see Logger.Named). Names are
+	// hierarchical: "http" also applies to "http.client" unless it has its own entry.
+	LoggerLevels map[string]slog.Level
+
+	// ContextExtractors derive attributes from the context of every record.
+	ContextExtractors []ContextExtractor
+
+	// Scan enables built-in content detectors that redact sensitive data inside any
+	// string value and the record message, independent of attribute keys.
+	Scan ScanKind
+	// ScanPatterns are additional user-supplied content detectors.
+	ScanPatterns []ScanPattern
+
+	// HashKey is the HMAC key used by MaskHash. Rotate it together with HashKeyID.
+	HashKey []byte
+	// HashKeyID is prefixed to every MaskHash token to tell which key produced it.
+	HashKeyID string
+
+	// TraceExtractor finds the active span in the context of a record; when one is
+	// found, trace_id, span_id and trace_flags are added to the record.
+	TraceExtractor TraceExtractor
+
+	// LogChanges writes a "logger reconfigured" record listing the changes
+	// (see DiffConfig) whenever the configuration is updated.
+	LogChanges bool
+
+	// Sampling thins out repeated records with the same message and level.
+	Sampling Sampling
+	// RateLimit caps the rate of records per key with a token bucket.
+	RateLimit RateLimit
+	// SuppressionSummary, if positive, is how often a summary record is written for
+	// every message and level (or RateLimit.Key) that had records dropped by Sampling
+	// or RateLimit. It is written through the logger of the last dropped record.
+	SuppressionSummary time.Duration
+
+	// Dedup suppresses duplicate records within a window (see Dedup).
+	Dedup Dedup
+
+	// FlightRecorder keeps records below Level in memory and writes them when an
+	// error is logged (see FlightRecorder).
+	FlightRecorder FlightRecorder
+
+	// Sinks fans records out to several destinations, each with its own level,
+	// format and extra mask/remove rules. When empty, Output and Format are used.
+	Sinks []Sink
+
+	// gen counts the log calls in flight on Output; it is replaced by SwapOutput.
+	gen *outputGen
 }
 
 // Clone creates a deep copy of the Config to ensure thread-safe updates.
@@ -52,9 +117,31 @@ func (c *Config) Clone() *Config {
 		newCfg.LevelNames[k] = v
 	}
 
+	newCfg.LoggerLevels = make(map[string]slog.Level, len(c.LoggerLevels))
+	for k, v := range c.LoggerLevels {
+		newCfg.LoggerLevels[k] = v
+	}
+
 	newCfg.ContextKeys = make([]string, len(c.ContextKeys))
 	copy(newCfg.ContextKeys, c.ContextKeys)
 
+	newCfg.ContextExtractors = make([]ContextExtractor, len(c.ContextExtractors))
+	copy(newCfg.ContextExtractors, c.ContextExtractors)
+
+	newCfg.ScanPatterns = make([]ScanPattern, len(c.ScanPatterns))
+	copy(newCfg.ScanPatterns, c.ScanPatterns)
+
+	newCfg.Dedup.Keys = make([]string, len(c.Dedup.Keys))
+	copy(newCfg.Dedup.Keys, c.Dedup.Keys)
+
+	newCfg.HashKey = make([]byte, len(c.HashKey))
+	copy(newCfg.HashKey, c.HashKey)
+
+	newCfg.Sinks = make([]Sink, len(c.Sinks))
+	for i, s := range c.Sinks {
+		newCfg.Sinks[i] = s.clone()
+	}
+
 	return &newCfg
 }
 
@@ -75,18 +162,19 @@ func WithOutput(w io.Writer) Option {
 	}
 }
 
-// WithFormat sets the log output format (Text or JSON).
+// WithFormat sets the log output format.
 func WithFormat(f Format) Option {
 	return func(o *options) {
 		o.initialConfig.Format = f
 	}
 }
 
-// ParseFormat converts a string representation to a Format type.
+// ParseFormat converts a string representation to a Format type using the format
+// registry (e.g. "json", "console", "logfmt", "gelf", "cbor" or a registered name).
 // It defaults to FormatText if the string is not recognized.
 func ParseFormat(s string) Format {
-	if strings.ToLower(s) == "json" {
-		return FormatJSON
+	if f, ok := LookupFormat(s); ok {
+		return f
 	}
 	return FormatText
 }
@@ -98,6 +186,13 @@ func WithLevel(l slog.Level) Option {
 	}
 }
 
+// WithLoggerLevel sets the level of the named logger and its children (see Logger.Named).
+func WithLoggerLevel(name string, l slog.Level) Option {
+	return func(o *options) {
+		o.initialConfig.LoggerLevels[name] = l
+	}
+}
+
 // WithMaskKey associates a single attribute key with a MaskType.
 func WithMaskKey(key string, mType MaskType) Option {
 	return func(o *options) {
@@ -154,12 +249,115 @@ func WithLevelNames(m LevelNames) Option {
 }
 
 // WithContextKeys registers keys to be automatically extracted from context.Context and logged.
+//
+// Deprecated: plain string context keys collide across packages (go vet reports them).
+// Use WithContextExtractor with a ContextKey, or ContextWith.
 func WithContextKeys(keys ...string) Option {
 	return func(o *options) {
 		o.initialConfig.ContextKeys = append(o.initialConfig.ContextKeys, keys...)
 	}
 }
 
+// WithContextExtractor registers functions that derive attributes from the context
+// of every record, e.g. ContextKey.Extract.
+func WithContextExtractor(fns ...ContextExtractor) Option {
+	return func(o *options) {
+		for _, fn := range fns {
+			if fn != nil {
+				o.initialConfig.ContextExtractors = append(o.initialConfig.ContextExtractors, fn)
+			}
+		}
+	}
+}
+
+// WithTraceExtractor correlates records with traces: when te finds a span in the
+// context passed to a *Context logging method, its IDs are added to the record.
+func WithTraceExtractor(te TraceExtractor) Option {
+	return func(o *options) {
+		o.initialConfig.TraceExtractor = te
+	}
+}
+
+// WithChangeLog writes a "logger reconfigured" record whenever the configuration changes.
+func WithChangeLog() Option {
+	return func(o *options) {
+		o.initialConfig.LogChanges = true
+	}
+}
+
+// WithSampling enables sampling of repeated records (see Sampling).
+func WithSampling(s Sampling) Option {
+	return func(o *options) {
+		o.initialConfig.Sampling = s
+	}
+}
+
+// WithRateLimit enables rate limiting of records per key (see RateLimit).
+func WithRateLimit(rl RateLimit) Option {
+	return func(o *options) {
+		o.initialConfig.RateLimit = rl
+	}
+}
+
+// WithSuppressionSummary writes a summary of the records dropped by sampling and
+// rate limiting every interval.
+func WithSuppressionSummary(interval time.Duration) Option {
+	return func(o *options) {
+		o.initialConfig.SuppressionSummary = interval
+	}
+}
+
+// WithDedup suppresses duplicate records within window, comparing the message, level
+// and the given attribute keys (see Dedup).
+func WithDedup(window time.Duration, keys ...string) Option {
+	return func(o *options) {
+		o.initialConfig.Dedup = Dedup{Window: window, Keys: append([]string(nil), keys...)}
+	}
+}
+
+// WithFlightRecorder keeps records below the level in memory and writes them when an
+// error is logged (see FlightRecorder).
+func WithFlightRecorder(fr FlightRecorder) Option {
+	return func(o *options) {
+		o.initialConfig.FlightRecorder = fr
+	}
+}
+
+// WithSink adds an output sink. Once any sink is configured, records are written
+// to the sinks only and Config.Output/Config.Format are ignored.
+func WithSink(s Sink) Option {
+	return func(o *options) {
+		o.initialConfig.Sinks = append(o.initialConfig.Sinks, s.clone())
+	}
+}
+
+// WithScan enables built-in content detectors (e.g. ScanEmail|ScanCard or ScanAll).
+func WithScan(kinds ScanKind) Option {
+	return func(o *options) {
+		o.initialConfig.Scan |= kinds
+	}
+}
+
+// WithScanPattern registers a custom content detector: every match of re is masked with mType.
+func WithScanPattern(name string, re *regexp.Regexp, mType MaskType) Option {
+	return func(o *options) {
+		if re != nil {
+			o.initialConfig.ScanPatterns = append(
+				o.initialConfig.ScanPatterns,
+				ScanPattern{Name: name, Pattern: re, Mask: mType},
+			)
+		}
+	}
+}
+
+// WithHashKey sets the HMAC key (and its identifier) used to pseudonymize MaskHash values.
+func WithHashKey(keyID string, key []byte) Option {
+	return func(o *options) {
+		o.initialConfig.HashKeyID = keyID
+		o.initialConfig.HashKey = append([]byte(nil), key...)
+	}
+}
+
 // defaultOptions provides the baseline configuration for a new logger.
 func defaultOptions() *options {
 	ln := make(LevelNames, len(defaultLevelNames))
@@ -176,6 +374,8 @@ func defaultOptions() *options {
 			RemoveKeys: make(RemoveMap),
 			LevelNames: ln,
 			Masker:     &DefaultMasker{},
+
+			LoggerLevels: make(map[string]slog.Level),
 		},
 	}
 }
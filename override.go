@@ -0,0 +1,145 @@
+package slogx
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Override is a temporary configuration change started with Logger.SetLevelFor or
+// Logger.UpdateConfigFor. It is reverted when its duration elapses or when it is cancelled.
+//
+// Overrides are layered over the base configuration in the order they were started:
+// the effective configuration is the base with every active override applied, so
+// overlapping overrides of the same field resolve to the most recent one, and when
+// an override ends the others stay in effect. UpdateConfig changes the base while
+// overrides are active; the change is visible once no override sets the same field.
+type Override struct {
+	l     *Logger
+	fn    func(*Config)
+	timer *time.Timer
+	done  chan struct{}
+	// expired is set when the override was reverted because its duration elapsed.
+	// It is written under state.cfgMu before done is closed.
+	expired bool
+}
+
+// SetLevelFor sets the logging threshold for d, then reverts it.
+func (l *Logger) SetLevelFor(lvl slog.Level, d time.Duration) *Override {
+	return l.UpdateConfigFor(
+		func(c *Config) {
+			c.Level = lvl
+		}, d,
+	)
+}
+
+// UpdateConfigFor applies fn to the configuration for d, then reverts it. fn may be
+// called again whenever the base configuration or another override changes, so it
+// must only modify the Config it is given.
+func (l *Logger) UpdateConfigFor(fn func(*Config), d time.Duration) *Override {
+	o, _ := l.startOverride(nil, false, fn, d)
+	return o
+}
+
+// startOverride starts a temporary override. If version is not nil, the override is
+// refused with ErrConfigConflict when the published configuration has a different version.
+// If validate is set, it is refused when the resulting configuration is invalid.
+func (l *Logger) startOverride(version *uint64, validate bool, fn func(*Config), d time.Duration) (*Override, error) {
+	o := &Override{l: l, fn: fn, done: make(chan struct{})}
+
+	st := l.state
+	defer l.deliver() // after cfgMu is released
+	st.cfgMu.Lock()
+	defer st.cfgMu.Unlock()
+
+	// From now on, updates change the base and wait for cfgMu; an update that loaded
+	// the configuration before fails its compare-and-swap and starts over
+	st.overridden.Store(true)
+	fail := func(err error) (*Override, error) {
+		if st.base == nil {
+			st.overridden.Store(false)
+		}
+		return nil, err
+	}
+
+	for {
+		cur := l.cfgPtr.Load()
+		if version != nil && cur.Version != *version {
+			return fail(ErrConfigConflict)
+		}
+
+		base := st.base
+		if base == nil {
+			base = cur
+		}
+		cfg := l.withOverrides(base)
+		fn(cfg)
+		if validate {
+			if err := cfg.Validate(); err != nil {
+				return fail(err)
+			}
+		}
+
+		if l.publish(cur, cfg) {
+			st.base = base
+			st.overrides = append(st.overrides, o)
+			o.timer = time.AfterFunc(d, func() { o.cancel(true) })
+			return o, nil
+		}
+	}
+}
+
+// Cancel reverts the override now. It reports whether the override was still active.
+func (o *Override) Cancel() bool {
+	return o.cancel(false)
+}
+
+// cancel reverts the override; expired is set when its timer fired.
+func (o *Override) cancel(expired bool) bool {
+	l, st := o.l, o.l.state
+	defer l.deliver() // after cfgMu is released
+	st.cfgMu.Lock()
+	defer st.cfgMu.Unlock()
+
+	i := -1
+	for j, active := range st.overrides {
+		if active == o {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		return false
+	}
+
+	o.timer.Stop()
+	st.overrides = append(st.overrides[:i:i], st.overrides[i+1:]...)
+
+	// Updates go through cfgMu while overrides are active, so this only retries
+	// after an update that loaded the configuration before the overrides started
+	cfg := l.withOverrides(st.base)
+	for !l.publish(l.cfgPtr.Load(), cfg) {
+	}
+	if len(st.overrides) == 0 {
+		st.base = nil
+		st.overridden.Store(false)
+	}
+
+	o.expired = expired
+	close(o.done)
+	return true
+}
+
+// Done returns a channel that is closed when the override has been reverted.
+func (o *Override) Done() <-chan struct{} {
+	return o.done
+}
+
+// withOverrides returns a copy of base with all active overrides applied.
+// state.cfgMu must be held.
+func (l *Logger) withOverrides(base *Config) *Config {
+	cfg := base.Clone()
+	for _, o := range l.state.overrides {
+		o.fn(cfg)
+	}
+	return cfg
+}
@@ -0,0 +1,111 @@
+package slogx
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_SetLevelFor(t *testing.T) {
+	l := New(WithOutput(&bytes.Buffer{}), WithLevel(slog.LevelInfo))
+	level := func() slog.Level { return l.cfgPtr.Load().Level }
+
+	o := l.SetLevelFor(LevelTrace, 50*time.Millisecond)
+	assert.Equal(t, LevelTrace, level())
+
+	select {
+	case <-o.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("override was not reverted")
+	}
+	assert.Equal(t, slog.LevelInfo, level())
+	assert.False(t, o.Cancel(), "an expired override cannot be cancelled")
+}
+
+func TestLogger_OverlappingOverrides(t *testing.T) {
+	l := New(WithOutput(&bytes.Buffer{}), WithLevel(slog.LevelInfo))
+	cfg := func() *Config { return l.cfgPtr.Load() }
+
+	long := l.SetLevelFor(LevelTrace, time.Hour)
+	short := l.UpdateConfigFor(
+		func(c *Config) {
+			c.Level = slog.LevelDebug
+			c.Format = FormatJSON
+		}, time.Hour,
+	)
+	assert.Equal(t, slog.LevelDebug, cfg().Level, "the most recent override wins")
+	assert.Equal(t, FormatJSON, cfg().Format)
+
+	// Permanent changes go to the base and survive the overrides
+	l.UpdateConfig(
+		func(c *Config) {
+			c.Level = slog.LevelWarn
+			c.MaskKeys["email"] = MaskEmail
+		},
+	)
+	assert.Equal(t, slog.LevelDebug, cfg().Level)
+	assert.Equal(t, MaskEmail, cfg().MaskKeys["email"])
+
+	require.True(t, short.Cancel())
+	assert.Equal(t, LevelTrace, cfg().Level, "the earlier override is still active")
+	assert.Equal(t, FormatText, cfg().Format)
+
+	require.True(t, long.Cancel())
+	assert.Equal(t, slog.LevelWarn, cfg().Level)
+	assert.Equal(t, MaskEmail, cfg().MaskKeys["email"])
+	assert.False(t, long.Cancel())
+
+	select {
+	case <-long.Done():
+	default:
+		t.Fatal("Done must be closed after Cancel")
+	}
+}
+
+func TestLogger_OverrideOutOfOrder(t *testing.T) {
+	l := New(WithOutput(&bytes.Buffer{}), WithLevel(slog.LevelInfo))
+
+	first := l.SetLevelFor(slog.LevelDebug, time.Hour)
+	second := l.SetLevelFor(LevelTrace, time.Hour)
+
+	require.True(t, first.Cancel())
+	assert.Equal(t, LevelTrace, l.cfgPtr.Load().Level)
+	require.True(t, second.Cancel())
+	assert.Equal(t, slog.LevelInfo, l.cfgPtr.Load().Level)
+}
+
+func TestLogger_OverridesConcurrentUpdates(t *testing.T) {
+	l := New(WithOutput(&bytes.Buffer{}), WithLevel(slog.LevelInfo))
+
+	const writers, updates = 4, 50
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < updates; i++ {
+				key := fmt.Sprintf("w%d_%d", w, i)
+				l.UpdateConfig(func(c *Config) { c.MaskKeys[key] = MaskDefault })
+			}
+		}(w)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < updates; i++ {
+			l.SetLevelFor(slog.LevelError, time.Hour).Cancel()
+		}
+	}()
+	wg.Wait()
+
+	cfg := l.Config()
+	assert.Len(t, cfg.MaskKeys, writers*updates, "updates made during overrides must reach the base")
+	assert.Equal(t, slog.LevelInfo, cfg.Level)
+	assert.False(t, l.state.overridden.Load())
+}
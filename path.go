@@ -0,0 +1,201 @@
+package slogx
+
+import (
+	"sort"
+	"strings"
+)
+
+// pathSep separates group names and the attribute key in a rule path (e.g. "user.email").
+const pathSep = "."
+
+const (
+	// wildcardSegment matches exactly one group name or key in a rule path.
+	wildcardSegment = "*"
+	// recursiveSegment matches zero or more group names in a rule path.
+	recursiveSegment = "**"
+)
+
+// pathPattern is a compiled rule path containing wildcard segments.
+type pathPattern[V any] struct {
+	segments  []string
+	literals  int
+	recursive int
+	value     V
+}
+
+// pathMatcher resolves attribute paths (groups + key) against a set of rule paths.
+//
+// Rules are matched in the following order, the first match wins:
+//  1. Exact dotted paths ("user.email")
+//  2. Wildcard paths ("*.token", "request.**.authorization"), most specific first
+//  3. Bare keys ("email"), which match the key at any group depth
+//
+// Groups come from both Logger.WithGroup and inline slog.Group attributes.
+// Dots inside attribute keys are treated as separators as well, so the rule
+// "http.method" matches both slog.String("http.method", ...) and the key
+// "method" inside the "http" group.
+type pathMatcher[V any] struct {
+	exact    map[string]V
+	bare     map[string]V
+	patterns []pathPattern[V]
+}
+
+// compilePaths precompiles a rule set keyed by dotted paths into a pathMatcher.
+func compilePaths[V any](rules map[string]V) *pathMatcher[V] {
+	m := &pathMatcher[V]{
+		exact: make(map[string]V),
+		bare:  make(map[string]V),
+	}
+
+	for rawPath, v := range rules {
+		path := normalizePath(rawPath)
+		if path == "" {
+			continue
+		}
+
+		segments := strings.Split(path, pathSep)
+		if len(segments) == 1 && !isWildcard(path) {
+			m.bare[path] = v
+			continue
+		}
+
+		p := pathPattern[V]{segments: segments, value: v}
+		for _, s := range segments {
+			switch s {
+			case recursiveSegment:
+				p.recursive++
+			case wildcardSegment:
+			default:
+				p.literals++
+			}
+		}
+
+		if p.literals == len(segments) {
+			m.exact[path] = v
+			continue
+		}
+		m.patterns = append(m.patterns, p)
+	}
+
+	// Most specific patterns first; the remaining keys only make the order deterministic.
+	sort.Slice(
+		m.patterns, func(i, j int) bool {
+			a, b := m.patterns[i], m.patterns[j]
+			if a.literals != b.literals {
+				return a.literals > b.literals
+			}
+			if a.recursive != b.recursive {
+				return a.recursive < b.recursive
+			}
+			if len(a.segments) != len(b.segments) {
+				return len(a.segments) > len(b.segments)
+			}
+			return strings.Join(a.segments, pathSep) < strings.Join(b.segments, pathSep)
+		},
+	)
+
+	return m
+}
+
+// match returns the value of the rule matching the attribute key under the given groups.
+func (m *pathMatcher[V]) match(groups []string, key string) (V, bool) {
+	if len(m.exact) > 0 || len(m.patterns) > 0 {
+		path := joinPath(groups, key)
+		if v, ok := m.exact[path]; ok {
+			return v, true
+		}
+
+		if len(m.patterns) > 0 {
+			segments := strings.Split(path, pathSep)
+			for _, p := range m.patterns {
+				if matchSegments(p.segments, segments) {
+					return p.value, true
+				}
+			}
+		}
+	}
+
+	v, ok := m.bare[key]
+	return v, ok
+}
+
+// matchSegments reports whether a path matches a pattern with "*" and "**" segments.
+func matchSegments(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case recursiveSegment:
+			rest := pattern[1:]
+			for i := 0; i <= len(path); i++ {
+				if matchSegments(rest, path[i:]) {
+					return true
+				}
+			}
+			return false
+		case wildcardSegment:
+			if len(path) == 0 {
+				return false
+			}
+		default:
+			if len(path) == 0 || path[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
+
+// joinPath builds the dotted path of an attribute without allocating for top-level keys.
+func joinPath(groups []string, key string) string {
+	if len(groups) == 0 {
+		return key
+	}
+	return strings.Join(groups, pathSep) + pathSep + key
+}
+
+// normalizePath trims whitespace and stray separators from a user-supplied rule path.
+func normalizePath(path string) string {
+	return strings.Trim(strings.TrimSpace(path), pathSep)
+}
+
+// rulePattern returns the segments of a rule path as matched by pathMatcher: a bare
+// key matches at any group depth, like "**.key".
+func rulePattern(rawPath string) []string {
+	path := normalizePath(rawPath)
+	if path == "" {
+		return nil
+	}
+	segments := strings.Split(path, pathSep)
+	if len(segments) == 1 && !isWildcard(path) {
+		return []string{recursiveSegment, path}
+	}
+	return segments
+}
+
+// rulesOverlap reports whether some attribute path is matched by both rule paths,
+// e.g. "email" and "user.email", or "*.email" and "user.**".
+func rulesOverlap(a, b string) bool {
+	pa, pb := rulePattern(a), rulePattern(b)
+	return len(pa) > 0 && len(pb) > 0 && overlapSegments(pa, pb)
+}
+
+// overlapSegments reports whether some path matches both patterns.
+func overlapSegments(a, b []string) bool {
+	switch {
+	case len(a) > 0 && a[0] == recursiveSegment:
+		// "**" matches nothing, or also the first segment matched by b
+		return overlapSegments(a[1:], b) || (len(b) > 0 && overlapSegments(a, b[1:]))
+	case len(b) > 0 && b[0] == recursiveSegment:
+		return overlapSegments(b, a)
+	case len(a) == 0 || len(b) == 0:
+		return len(a) == len(b)
+	case a[0] != wildcardSegment && b[0] != wildcardSegment && a[0] != b[0]:
+		return false
+	default:
+		return overlapSegments(a[1:], b[1:])
+	}
+}
+
+func isWildcard(segment string) bool {
+	return segment == wildcardSegment || segment == recursiveSegment
+}
@@ -0,0 +1,73 @@
+package slogx
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathMatcher(t *testing.T) {
+	m := compilePaths(
+		MaskMap{
+			"email":                    MaskEmail,
+			"user.email":               MaskSecret,
+			"*.token":                  MaskSecret,
+			"request.**.authorization": MaskDefault,
+		},
+	)
+
+	cases := []struct {
+		groups []string
+		key    string
+		want   MaskType
+		ok     bool
+	}{
+		{nil, "email", MaskEmail, true},
+		{[]string{"user"}, "email", MaskSecret, true},
+		{[]string{"billing", "contact"}, "email", MaskEmail, true},
+		{[]string{"auth"}, "token", MaskSecret, true},
+		{nil, "token", 0, false},
+		{[]string{"a", "b"}, "token", 0, false},
+		{[]string{"request"}, "authorization", MaskDefault, true},
+		{[]string{"request", "headers"}, "authorization", MaskDefault, true},
+		{[]string{"response", "headers"}, "authorization", 0, false},
+		{nil, "user.email", MaskSecret, true},
+	}
+
+	for _, c := range cases {
+		got, ok := m.match(c.groups, c.key)
+		assert.Equal(t, c.ok, ok, "%v %s", c.groups, c.key)
+		if c.ok {
+			assert.Equal(t, c.want, got, "%v %s", c.groups, c.key)
+		}
+	}
+}
+
+func TestHandler_GroupPathRules(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(
+		WithOutput(buf),
+		WithFormat(FormatText),
+		WithMaskRules(NewMaskRules().Add("user.email", MaskEmail)),
+		WithRemoval(NewRemovalSet().Add("*.token")),
+	)
+
+	l.Info(
+		"grouped",
+		slog.Group("user", slog.String("email", "antonioh@gmail.com"), slog.String("token", "t1")),
+		slog.Group("billing", slog.String("email", "billing@corp.com")),
+		slog.String("token", "top"),
+	)
+
+	out := buf.String()
+	assert.Contains(t, out, "user.email=an***h@gmail.com")
+	assert.Contains(t, out, "billing.email=billing@corp.com")
+	assert.NotContains(t, out, "t1")
+	assert.Contains(t, out, "token=top")
+	buf.Reset()
+
+	l.WithGroup("user").Info("with group", "email", "antonioh@gmail.com")
+	assert.Contains(t, buf.String(), "user.email=an***h@gmail.com")
+}
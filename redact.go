@@ -0,0 +1,354 @@
+package slogx
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// maxRedactDepth limits how deep the redactor descends into nested values.
+// Anything deeper is replaced with redactTruncated so it cannot leak.
+const maxRedactDepth = 16
+
+const (
+	// redactCycle replaces a pointer that refers back to one of its parents.
+	redactCycle = "[CYCLE]"
+	// redactTruncated replaces values nested deeper than maxRedactDepth.
+	redactTruncated = "[TRUNCATED]"
+)
+
+// tagName is the struct tag used by domain types to declare their redaction policy:
+//
+//	Email    string `slogx:"mask=email"` // masked with MaskEmail
+//	Password string `slogx:"-"`          // never logged
+//	Token    string `slogx:"mask"`       // masked with MaskDefault
+const tagName = "slogx"
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	errorType         = reflect.TypeFor[error]()
+)
+
+// redactor walks structs, maps, slices and pointers passed via slog.Any and applies
+// RemoveKeys, MaskKeys and slogx struct tags to nested field names.
+//
+// Nested fields are matched by path: a field "email" of a struct logged under the
+// key "user" has the path "user.email", so both the bare rule "email" and the path
+// rule "user.email" apply to it.
+type redactor struct {
+	mask   *pathMatcher[MaskType]
+	remove *pathMatcher[struct{}]
+	masker Masker
+	// scan redacts string leaves by content; nil disables content scanning.
+	scan *contentScanner
+}
+
+// redact returns a sanitized copy of value and true if anything had to be removed
+// or masked. Values that need no changes are returned untouched, so their output
+// format stays exactly what the underlying handler would produce.
+func (r *redactor) redact(groups []string, key string, value any) (any, bool) {
+	if value == nil {
+		return nil, false
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		return nil, false
+	}
+
+	path := make([]string, len(groups), len(groups)+4)
+	copy(path, groups)
+	path = append(path, key)
+
+	w := &redactWalk{redactor: r}
+	return w.walk(path, v, 0)
+}
+
+// redactWalk holds the state of a single redact call.
+type redactWalk struct {
+	*redactor
+	seen map[uintptr]struct{}
+}
+
+func (w *redactWalk) walk(path []string, v reflect.Value, depth int) (any, bool) {
+	if !v.IsValid() || isOpaque(v.Type()) {
+		return nil, false
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil, false
+		}
+		return w.walk(path, v.Elem(), depth)
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil, false
+		}
+		ptr := v.Pointer()
+		if _, ok := w.seen[ptr]; ok {
+			return redactCycle, true
+		}
+		if w.seen == nil {
+			w.seen = make(map[uintptr]struct{})
+		}
+		w.seen[ptr] = struct{}{}
+		out, changed := w.walk(path, v.Elem(), depth)
+		delete(w.seen, ptr)
+		return out, changed
+
+	case reflect.Struct:
+		if depth >= maxRedactDepth {
+			return redactTruncated, true
+		}
+		return w.walkStruct(path, v, depth)
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, false
+		}
+		if depth >= maxRedactDepth {
+			return redactTruncated, true
+		}
+		return w.walkMap(path, v, depth)
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, false
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return nil, false
+		}
+		if depth >= maxRedactDepth {
+			return redactTruncated, true
+		}
+		return w.walkSlice(path, v, depth)
+
+	case reflect.String:
+		if w.scan != nil {
+			if out, changed := w.scan.scan(v.String()); changed {
+				return out, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func (w *redactWalk) walkStruct(path []string, v reflect.Value, depth int) (any, bool) {
+	fields := cachedFields(v.Type())
+	out := make(redactedStruct, 0, len(fields))
+	changed := false
+
+	for _, f := range fields {
+		fv := v.Field(f.index)
+		if f.remove {
+			changed = true
+			continue
+		}
+		if f.mask {
+			out = append(out, redactedField{name: f.name, value: w.masker.Mask(fv.Interface(), f.maskType)})
+			changed = true
+			continue
+		}
+
+		val, fieldChanged := w.field(path, f.name, fv, depth)
+		changed = changed || fieldChanged
+		if val != omitField {
+			out = append(out, redactedField{name: f.name, value: val})
+		}
+	}
+
+	if !changed {
+		return nil, false
+	}
+	return out, true
+}
+
+func (w *redactWalk) walkMap(path []string, v reflect.Value, depth int) (any, bool) {
+	out := make(map[string]any, v.Len())
+	changed := false
+
+	iter := v.MapRange()
+	for iter.Next() {
+		name := fmt.Sprint(iter.Key().Interface())
+		val, fieldChanged := w.field(path, name, iter.Value(), depth)
+		changed = changed || fieldChanged
+		if val != omitField {
+			out[name] = val
+		}
+	}
+
+	if !changed {
+		return nil, false
+	}
+	return out, true
+}
+
+func (w *redactWalk) walkSlice(path []string, v reflect.Value, depth int) (any, bool) {
+	out := make([]any, v.Len())
+	changed := false
+
+	for i := range out {
+		ev := v.Index(i)
+		val, elemChanged := w.walk(path, ev, depth+1)
+		if elemChanged {
+			out[i] = val
+			changed = true
+		} else {
+			out[i] = ev.Interface()
+		}
+	}
+
+	if !changed {
+		return nil, false
+	}
+	return out, true
+}
+
+// omitField is returned by field when the rule set removes the field entirely.
+var omitField = &struct{ omit bool }{true}
+
+// field applies RemoveKeys and MaskKeys to a named member of a struct or map and
+// descends into it otherwise. It always returns the value to emit (or omitField).
+func (w *redactWalk) field(path []string, name string, fv reflect.Value, depth int) (any, bool) {
+	if _, ok := w.remove.match(path, name); ok {
+		return omitField, true
+	}
+	if mType, ok := w.mask.match(path, name); ok {
+		return w.masker.Mask(fv.Interface(), mType), true
+	}
+
+	val, changed := w.walk(append(path, name), fv, depth+1)
+	if !changed {
+		return fv.Interface(), false
+	}
+	return val, true
+}
+
+// isOpaque reports whether values of type t must be logged as-is because they
+// control their own representation.
+func isOpaque(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) || t.Implements(errorType)
+}
+
+// structField describes an exported struct field as seen by the redactor.
+type structField struct {
+	index    int
+	name     string
+	remove   bool
+	mask     bool
+	maskType MaskType
+}
+
+// fieldCache stores parsed struct layouts keyed by reflect.Type.
+var fieldCache sync.Map
+
+// cachedFields returns the exported fields of t with their output names and slogx tags.
+// Field names follow encoding/json: the json tag name if present, otherwise the Go name.
+func cachedFields(t reflect.Type) []structField {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]structField)
+	}
+
+	fields := make([]structField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := sf.Name
+		if tag, ok := sf.Tag.Lookup("json"); ok {
+			jsonName, _, _ := strings.Cut(tag, ",")
+			if jsonName == "-" {
+				continue
+			}
+			if jsonName != "" {
+				name = jsonName
+			}
+		}
+
+		f := structField{index: i, name: name}
+		parseFieldTag(sf.Tag.Get(tagName), &f)
+		fields = append(fields, f)
+	}
+
+	cached, _ := fieldCache.LoadOrStore(t, fields)
+	return cached.([]structField)
+}
+
+// parseFieldTag applies the options of a slogx struct tag to f.
+// Unknown mask names fall back to MaskDefault so a typo never leaks data.
+func parseFieldTag(tag string, f *structField) {
+	if tag == "" {
+		return
+	}
+	for _, opt := range strings.Split(tag, ",") {
+		opt = strings.TrimSpace(opt)
+		name, value, _ := strings.Cut(opt, "=")
+		switch name {
+		case "-":
+			f.remove = true
+		case "mask":
+			f.mask = true
+			if mType, ok := LookupMaskType(value); ok {
+				f.maskType = mType
+			}
+		}
+	}
+}
+
+// redactedStruct is the sanitized replacement of a struct value.
+// It keeps the field order in both JSON and text output.
+type redactedStruct []redactedField
+
+type redactedField struct {
+	name  string
+	value any
+}
+
+// MarshalJSON encodes the fields as a JSON object in declaration order.
+func (s redactedStruct) MarshalJSON() ([]byte, error) {
+	buf := []byte{'{'}
+	for i, f := range s {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		name, err := json.Marshal(f.name)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, name...)
+		buf = append(buf, ':')
+		buf = append(buf, val...)
+	}
+	return append(buf, '}'), nil
+}
+
+// String renders the fields the same way fmt's %+v renders a struct.
+func (s redactedStruct) String() string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, f := range s {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(f.name)
+		b.WriteByte(':')
+		fmt.Fprintf(&b, "%+v", f.value)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package slogx
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAddress struct {
+	City  string
+	Phone string `json:"phone"`
+}
+
+type testUser struct {
+	Name     string       `json:"name"`
+	Email    string       `json:"email" slogx:"mask=email"`
+	Password string       `json:"password" slogx:"-"`
+	Token    string       `json:"token"`
+	Address  *testAddress `json:"address"`
+	Tags     []string     `json:"tags"`
+	Parent   *testUser    `json:"parent,omitempty"`
+}
+
+func TestRedactor_Structs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(
+		WithOutput(buf),
+		WithFormat(FormatJSON),
+		WithMaskKey("phone", MaskPhone),
+		WithRemoval(NewRemovalSet().Add("user.token")),
+	)
+
+	u := &testUser{
+		Name:     "bob",
+		Email:    "antonioh@gmail.com",
+		Password: "hunter2",
+		Token:    "tkn",
+		Address:  &testAddress{City: "Paris", Phone: "+7 911 222 3456"},
+		Tags:     []string{"a"},
+	}
+	l.Info("struct", slog.Any("user", u))
+
+	var rec map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &rec))
+
+	user := rec["user"].(map[string]any)
+	assert.Equal(t, "bob", user["name"])
+	assert.Equal(t, "an***h@gmail.com", user["email"])
+	assert.NotContains(t, user, "password")
+	assert.NotContains(t, user, "token")
+	assert.Equal(t, "Paris", user["address"].(map[string]any)["City"])
+	assert.NotContains(t, buf.String(), "911 222")
+	assert.Equal(t, []any{"a"}, user["tags"])
+}
+
+func TestRedactor_MapsSlicesAndCycles(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(WithOutput(buf), WithFormat(FormatText), WithMaskKey("secret", MaskSecret))
+
+	l.Info("map", slog.Any("items", []map[string]any{{"id": 1, "secret": "s3cr3t"}}))
+	assert.Contains(t, buf.String(), "[SECRET]")
+	assert.NotContains(t, buf.String(), "s3cr3t")
+	buf.Reset()
+
+	u := &testUser{Name: "loop", Password: "p"}
+	u.Parent = u
+	l.Info("cycle", slog.Any("user", u))
+	assert.Contains(t, buf.String(), redactCycle)
+	assert.NotContains(t, buf.String(), "Password")
+	buf.Reset()
+
+	// Values without anything to redact keep their original representation.
+	l.Info("plain", slog.Any("addr", testAddress{City: "Rome"}))
+	assert.Contains(t, buf.String(), `addr="{City:Rome Phone:}"`)
+}
@@ -0,0 +1,187 @@
+package slogx
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// MaskStrategy redacts a single value. Strategies are registered by name with
+// RegisterMaskType and then used like the built-in mask types.
+type MaskStrategy interface {
+	Mask(value any) any
+}
+
+// MaskFunc adapts an ordinary function to the MaskStrategy interface.
+type MaskFunc func(value any) any
+
+// Mask calls f(value).
+func (f MaskFunc) Mask(value any) any {
+	return f(value)
+}
+
+// namedRegistry maps case-insensitive names to dense identifiers of type K (such as
+// MaskType or Format) and their values. Snapshots are immutable and replaced
+// copy-on-write, so lookups on the logging path are lock-free.
+type namedRegistry[K ~int, V any] struct {
+	mu   sync.Mutex
+	snap atomic.Pointer[registrySnapshot[K, V]]
+}
+
+// registrySnapshot is one immutable state of a namedRegistry. An empty name marks
+// an identifier that is not registered.
+type registrySnapshot[K ~int, V any] struct {
+	names  []string
+	values []V
+	byName map[string]K
+}
+
+// load returns the current snapshot.
+func (r *namedRegistry[K, V]) load() *registrySnapshot[K, V] {
+	if s := r.snap.Load(); s != nil {
+		return s
+	}
+	return &registrySnapshot[K, V]{}
+}
+
+// update publishes a copy of the current snapshot modified by fn.
+func (r *namedRegistry[K, V]) update(fn func(s *registrySnapshot[K, V])) {
+	old := r.load()
+	s := &registrySnapshot[K, V]{
+		names:  append([]string(nil), old.names...),
+		values: append([]V(nil), old.values...),
+		byName: make(map[string]K, len(old.byName)+1),
+	}
+	for k, v := range old.byName {
+		s.byName[k] = v
+	}
+	fn(s)
+	r.snap.Store(s)
+}
+
+// register adds name with v and returns its identifier. Registering an existing
+// name replaces its value and returns the same identifier.
+func (r *namedRegistry[K, V]) register(name string, v V) K {
+	name = normalizeRegistryName(name)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var id K
+	r.update(
+		func(s *registrySnapshot[K, V]) {
+			var ok bool
+			if id, ok = s.byName[name]; ok {
+				s.values[id] = v
+				return
+			}
+			id = K(len(s.names))
+			s.names = append(s.names, name)
+			s.values = append(s.values, v)
+			s.byName[name] = id
+		},
+	)
+	return id
+}
+
+// lookup returns the identifier registered under name.
+func (r *namedRegistry[K, V]) lookup(name string) (K, bool) {
+	id, ok := r.load().byName[normalizeRegistryName(name)]
+	return id, ok
+}
+
+// name returns the name registered for id.
+func (r *namedRegistry[K, V]) name(id K) (string, bool) {
+	s := r.load()
+	if id < 0 || int(id) >= len(s.names) || s.names[id] == "" {
+		return "", false
+	}
+	return s.names[id], true
+}
+
+// value returns the value registered for id.
+func (r *namedRegistry[K, V]) value(id K) (V, bool) {
+	s := r.load()
+	if id < 0 || int(id) >= len(s.names) || s.names[id] == "" {
+		var zero V
+		return zero, false
+	}
+	return s.values[id], true
+}
+
+func normalizeRegistryName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// maskTypes holds the built-in and registered mask strategies.
+var maskTypes namedRegistry[MaskType, MaskStrategy]
+
+func init() {
+	builtins := []struct {
+		mType    MaskType
+		name     string
+		strategy MaskStrategy
+	}{
+		{MaskDefault, "default", MaskFunc(func(any) any { return "[MASKED]" })},
+		{MaskEmail, "email", MaskFunc(func(v any) any { return maskEmail(fmt.Sprintf("%v", v)) })},
+		{MaskPhone, "phone", MaskFunc(func(v any) any { return maskPhone(fmt.Sprintf("%v", v)) })},
+		{MaskCard, "card", MaskFunc(func(v any) any { return maskCard(fmt.Sprintf("%v", v)) })},
+		{MaskSecret, "secret", MaskFunc(func(any) any { return "[SECRET]" })},
+		{MaskHash, "hash", MaskFunc(func(any) any { return "[MASKED]" })},
+	}
+	for _, b := range builtins {
+		if maskTypes.register(b.name, b.strategy) != b.mType {
+			panic("slogx: built-in mask types must be registered in iota order")
+		}
+	}
+}
+
+// RegisterMaskType registers a named masking strategy and returns its MaskType.
+// The returned value can be used anywhere a built-in MaskType is accepted
+// (MaskRules.Add, WithMaskKey, ScanPattern, ...), and the name can be used in
+// slogx struct tags and configuration files.
+//
+// Names are case-insensitive. Registering an existing name (including a built-in
+// one such as "email") replaces its strategy and returns the same MaskType.
+func RegisterMaskType(name string, s MaskStrategy) MaskType {
+	if normalizeRegistryName(name) == "" || s == nil {
+		panic("slogx: RegisterMaskType requires a name and a strategy")
+	}
+	return maskTypes.register(name, s)
+}
+
+// LookupMaskType returns the MaskType registered under name (case-insensitive).
+func LookupMaskType(name string) (MaskType, bool) {
+	return maskTypes.lookup(name)
+}
+
+// String returns the registered name of the mask type.
+func (t MaskType) String() string {
+	if name, ok := maskTypes.name(t); ok {
+		return name
+	}
+	return fmt.Sprintf("MaskType(%d)", int(t))
+}
+
+// MarshalText implements encoding.TextMarshaler so mask types are written by name.
+func (t MaskType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler so mask types can be read by name
+// from configuration files.
+func (t *MaskType) UnmarshalText(text []byte) error {
+	mType, ok := LookupMaskType(string(text))
+	if !ok {
+		return fmt.Errorf("slogx: unknown mask type %q", text)
+	}
+	*t = mType
+	return nil
+}
+
+// lookupMaskStrategy returns the strategy registered for mType, or nil.
+func lookupMaskStrategy(mType MaskType) MaskStrategy {
+	s, _ := maskTypes.value(mType)
+	return s
+}
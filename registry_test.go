@@ -0,0 +1,86 @@
+package slogx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unregister removes name so that tests leave the process-wide registries as they
+// found them. The identifier is reused only if it was the last one registered.
+func (r *namedRegistry[K, V]) unregister(name string) {
+	name = normalizeRegistryName(name)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.update(
+		func(s *registrySnapshot[K, V]) {
+			id, ok := s.byName[name]
+			if !ok {
+				return
+			}
+			delete(s.byName, name)
+			var zero V
+			s.names[id], s.values[id] = "", zero
+			for len(s.names) > 0 && s.names[len(s.names)-1] == "" {
+				s.names, s.values = s.names[:len(s.names)-1], s.values[:len(s.values)-1]
+			}
+		},
+	)
+}
+
+func TestNamedRegistry(t *testing.T) {
+	var r namedRegistry[int, string]
+	a, b := r.register("A", "a"), r.register(" b ", "b")
+	assert.Equal(t, []int{0, 1}, []int{a, b})
+	assert.Equal(t, a, r.register("a", "a2"), "names are case-insensitive")
+
+	v, ok := r.value(a)
+	assert.True(t, ok)
+	assert.Equal(t, "a2", v)
+
+	r.unregister("a")
+	_, ok = r.lookup("a")
+	assert.False(t, ok)
+	_, ok = r.name(a)
+	assert.False(t, ok, "identifiers before the last one are not reused")
+	r.unregister("b")
+	assert.Equal(t, 0, r.register("c", "c"), "trailing identifiers are reused")
+}
+
+func TestRegisterMaskType(t *testing.T) {
+	t.Cleanup(func() { maskTypes.unregister("test-iban") })
+	maskIBAN := RegisterMaskType(
+		"test-iban", MaskFunc(
+			func(v any) any {
+				s := fmt.Sprint(v)
+				return s[:4] + strings.Repeat("*", len(s)-4)
+			},
+		),
+	)
+
+	assert.Greater(t, int(maskIBAN), int(MaskSecret))
+	assert.Equal(t, "test-iban", maskIBAN.String())
+	assert.Equal(t, maskIBAN, RegisterMaskType("TEST-IBAN", MaskFunc(func(v any) any { return "x" })))
+
+	mType, ok := LookupMaskType("Email")
+	assert.True(t, ok)
+	assert.Equal(t, MaskEmail, mType)
+
+	var m MaskMap
+	require.NoError(t, json.Unmarshal([]byte(`{"iban":"test-iban","email":"email"}`), &m))
+	assert.Equal(t, MaskMap{"iban": maskIBAN, "email": MaskEmail}, m)
+	assert.Error(t, json.Unmarshal([]byte(`{"iban":"nope"}`), &m))
+
+	buf := &bytes.Buffer{}
+	l := New(WithOutput(buf), WithMaskRules(NewMaskRules().Add("iban", maskIBAN).Add("email", MaskEmail)))
+	l.Info("transfer", "iban", "DE89370400440532013000", "email", "antonioh@gmail.com")
+	assert.Contains(t, buf.String(), "iban=x")
+	assert.Contains(t, buf.String(), "email=an***h@gmail.com")
+}
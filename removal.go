@@ -12,6 +12,7 @@ func NewRemovalSet(keys ...string) *RemovalSet {
 }
 
 // Add appends one or more keys to the removal set.
+// Keys accept the same dotted group paths and wildcards as MaskRules.Add.
 func (s *RemovalSet) Add(keys ...string) *RemovalSet {
 	s.keys = append(s.keys, keys...)
 	return s
@@ -0,0 +1,189 @@
+package slogx
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ScanKind is a bit set of built-in detectors used for content scanning.
+// Unlike MaskKeys, detectors look at the content of string values regardless of
+// their attribute key, including the record message.
+type ScanKind uint
+
+const (
+	// ScanEmail detects email addresses and masks them with MaskEmail.
+	ScanEmail ScanKind = 1 << iota
+	// ScanCard detects Luhn-valid payment card numbers (PANs) and masks them with MaskCard.
+	ScanCard
+	// ScanPhone detects international (+...) and NANP phone numbers and masks them with MaskPhone.
+	ScanPhone
+	// ScanIBAN detects checksum-valid IBANs and masks them with MaskSecret.
+	ScanIBAN
+	// ScanJWT detects JSON Web Tokens and masks them with MaskSecret.
+	ScanJWT
+	// ScanAWSKey detects AWS-style access key IDs and masks them with MaskSecret.
+	ScanAWSKey
+
+	// ScanNone disables all built-in detectors.
+	ScanNone ScanKind = 0
+	// ScanAll enables every built-in detector.
+	ScanAll = ScanEmail | ScanCard | ScanPhone | ScanIBAN | ScanJWT | ScanAWSKey
+)
+
+// ScanPattern is a user-supplied detector for content scanning.
+// Every match of Pattern is replaced with the result of masking it with Mask.
+type ScanPattern struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Mask    MaskType
+}
+
+var (
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	cardPattern   = regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`)
+	phonePattern  = regexp.MustCompile(`\+\d[\d ().\-]{6,}\d|\(\d{3}\) ?\d{3}[ .\-]\d{4}\b|\b\d{3}[.\-]\d{3}[.\-]\d{4}\b`)
+	ibanPattern   = regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,3})?\b`)
+	jwtPattern    = regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]+\.eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+`)
+	awsKeyPattern = regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA|AIPA)[A-Z0-9]{16}\b`)
+)
+
+// detector is a single compiled scanning rule.
+type detector struct {
+	pattern *regexp.Regexp
+	mType   MaskType
+	// valid filters out false positives (e.g. Luhn check for cards); nil accepts every match.
+	valid func(string) bool
+	// hint is a cheap pre-check to skip the regexp entirely; nil always runs it.
+	hint func(string) bool
+}
+
+// contentScanner redacts sensitive data found inside string values.
+type contentScanner struct {
+	detectors []detector
+	masker    Masker
+}
+
+// newContentScanner compiles the enabled detectors of cfg; matches are redacted with masker.
+// It returns nil if content scanning is disabled.
+func newContentScanner(cfg *Config, masker Masker) *contentScanner {
+	if cfg.Scan == ScanNone && len(cfg.ScanPatterns) == 0 {
+		return nil
+	}
+
+	s := &contentScanner{masker: masker}
+
+	// Order matters: structured tokens go first so that e.g. digits inside an
+	// IBAN are not picked up by the card or phone detectors.
+	if cfg.Scan&ScanJWT != 0 {
+		s.detectors = append(s.detectors, detector{pattern: jwtPattern, mType: MaskSecret})
+	}
+	if cfg.Scan&ScanAWSKey != 0 {
+		s.detectors = append(s.detectors, detector{pattern: awsKeyPattern, mType: MaskSecret})
+	}
+	if cfg.Scan&ScanEmail != 0 {
+		s.detectors = append(s.detectors, detector{pattern: emailPattern, mType: MaskEmail, hint: hasAt})
+	}
+	if cfg.Scan&ScanIBAN != 0 {
+		s.detectors = append(s.detectors, detector{pattern: ibanPattern, mType: MaskSecret, valid: validIBAN, hint: hasDigit})
+	}
+	if cfg.Scan&ScanCard != 0 {
+		s.detectors = append(s.detectors, detector{pattern: cardPattern, mType: MaskCard, valid: validLuhn, hint: hasDigit})
+	}
+	if cfg.Scan&ScanPhone != 0 {
+		s.detectors = append(s.detectors, detector{pattern: phonePattern, mType: MaskPhone, valid: validPhone, hint: hasDigit})
+	}
+
+	for _, p := range cfg.ScanPatterns {
+		if p.Pattern != nil {
+			s.detectors = append(s.detectors, detector{pattern: p.Pattern, mType: p.Mask})
+		}
+	}
+
+	return s
+}
+
+// scan returns s with every detected match masked and reports whether anything changed.
+func (s *contentScanner) scan(str string) (string, bool) {
+	changed := false
+	for _, d := range s.detectors {
+		if d.hint != nil && !d.hint(str) {
+			continue
+		}
+		str = d.pattern.ReplaceAllStringFunc(
+			str, func(match string) string {
+				if d.valid != nil && !d.valid(match) {
+					return match
+				}
+				changed = true
+				return fmt.Sprint(s.masker.Mask(match, d.mType))
+			},
+		)
+	}
+	return str, changed
+}
+
+// validLuhn reports whether the digits of s form a 13–19 digit number with a valid Luhn checksum.
+func validLuhn(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && n <= 19 && sum%10 == 0
+}
+
+// validIBAN reports whether s is an IBAN with a valid ISO 13616 mod-97 checksum.
+func validIBAN(s string) bool {
+	s = strings.ReplaceAll(s, " ", "")
+	if len(s) < 15 || len(s) > 34 {
+		return false
+	}
+
+	// Move the country code and check digits to the end and compute the remainder
+	// digit by digit, expanding letters to two-digit numbers (A=10 ... Z=35).
+	rearranged := s[4:] + s[:4]
+	rem := 0
+	for i := 0; i < len(rearranged); i++ {
+		c := rearranged[i]
+		switch {
+		case c >= '0' && c <= '9':
+			rem = (rem*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			rem = (rem*100 + int(c-'A') + 10) % 97
+		default:
+			return false
+		}
+	}
+	return rem == 1
+}
+
+// validPhone reports whether s contains a plausible number of digits for a phone number (E.164 allows up to 15).
+func validPhone(s string) bool {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			n++
+		}
+	}
+	return n >= 8 && n <= 15
+}
+
+func hasAt(s string) bool {
+	return strings.IndexByte(s, '@') >= 0
+}
+
+func hasDigit(s string) bool {
+	return strings.ContainsAny(s, "0123456789")
+}
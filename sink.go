@@ -0,0 +1,146 @@
+package slogx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+)
+
+// Sink is a named output destination with its own threshold, format and sanitization rules.
+// When Config.Sinks is non-empty, every record is fanned out to all sinks instead of
+// Config.Output/Config.Format.
+type Sink struct {
+	// Name identifies the sink, e.g. for lookups with Config.Sink during UpdateConfig.
+	Name string
+	// Output is the destination writer. Sinks without an output are skipped.
+	Output io.Writer
+	// Level is the minimum level for this sink on top of Config.Level; nil accepts
+	// everything that passes Config.Level.
+	Level slog.Leveler
+	// Format is the output format of this sink.
+	Format Format
+	// MaskKeys are added to Config.MaskKeys for this sink; a path present in both uses the sink's MaskType.
+	MaskKeys MaskMap
+	// RemoveKeys are added to Config.RemoveKeys for this sink.
+	RemoveKeys RemoveMap
+}
+
+// clone returns a deep copy of the sink's rule maps.
+func (s Sink) clone() Sink {
+	if s.MaskKeys != nil {
+		m := make(MaskMap, len(s.MaskKeys))
+		for k, v := range s.MaskKeys {
+			m[k] = v
+		}
+		s.MaskKeys = m
+	}
+	if s.RemoveKeys != nil {
+		m := make(RemoveMap, len(s.RemoveKeys))
+		for k, v := range s.RemoveKeys {
+			m[k] = v
+		}
+		s.RemoveKeys = m
+	}
+	return s
+}
+
+// Sink returns the sink with the given name, or nil. The returned pointer refers
+// into c.Sinks, so it can be modified inside UpdateConfig.
+func (c *Config) Sink(name string) *Sink {
+	for i := range c.Sinks {
+		if c.Sinks[i].Name == name {
+			return &c.Sinks[i]
+		}
+	}
+	return nil
+}
+
+// mergeMaskKeys returns the union of base and extra, extra taking precedence.
+func mergeMaskKeys(base, extra MaskMap) MaskMap {
+	if len(extra) == 0 {
+		return base
+	}
+	m := make(MaskMap, len(base)+len(extra))
+	for k, v := range base {
+		m[k] = v
+	}
+	for k, v := range extra {
+		m[k] = v
+	}
+	return m
+}
+
+// mergeRemoveKeys returns the union of base and extra.
+func mergeRemoveKeys(base, extra RemoveMap) RemoveMap {
+	if len(extra) == 0 {
+		return base
+	}
+	m := make(RemoveMap, len(base)+len(extra))
+	for k, v := range base {
+		m[k] = v
+	}
+	for k, v := range extra {
+		m[k] = v
+	}
+	return m
+}
+
+// sinkHandler is a built sink: a format handler plus its level filter.
+type sinkHandler struct {
+	handler slog.Handler
+	level   slog.Leveler
+}
+
+func (s sinkHandler) accepts(level slog.Level) bool {
+	return s.level == nil || level >= s.level.Level()
+}
+
+// fanoutHandler forwards every record to all sinks that accept its level.
+// WithAttrs and WithGroup are propagated to every sink.
+type fanoutHandler struct {
+	sinks []sinkHandler
+}
+
+// Enabled reports whether at least one sink accepts the level.
+func (f *fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, s := range f.sinks {
+		if s.accepts(level) && s.handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle writes the record to every sink that accepts its level.
+// Errors of individual sinks are joined; a failing sink does not stop the others.
+func (f *fanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, s := range f.sinks {
+		if !s.accepts(r.Level) {
+			continue
+		}
+		if err := s.handler.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithAttrs applies the attributes to every sink.
+func (f *fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	sinks := make([]sinkHandler, len(f.sinks))
+	for i, s := range f.sinks {
+		sinks[i] = sinkHandler{handler: s.handler.WithAttrs(attrs), level: s.level}
+	}
+	return &fanoutHandler{sinks: sinks}
+}
+
+// WithGroup applies the group to every sink.
+func (f *fanoutHandler) WithGroup(name string) slog.Handler {
+	sinks := make([]sinkHandler, len(f.sinks))
+	for i, s := range f.sinks {
+		sinks[i] = sinkHandler{handler: s.handler.WithGroup(name), level: s.level}
+	}
+	return &fanoutHandler{sinks: sinks}
+}
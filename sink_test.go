@@ -0,0 +1,65 @@
+package slogx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSinks_FanOut(t *testing.T) {
+	console, file, audit := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
+	l := New(
+		WithLevel(slog.LevelDebug),
+		WithMaskKey("email", MaskEmail),
+		WithSink(Sink{Name: "console", Output: console, Format: FormatText}),
+		WithSink(Sink{Name: "file", Output: file, Format: FormatJSON, RemoveKeys: RemoveMap{"ip": {}}}),
+		WithSink(Sink{Name: "audit", Output: audit, Format: FormatJSON, Level: slog.LevelError}),
+	)
+
+	l.With("ip", "10.0.0.1").Info("login", "email", "antonioh@gmail.com")
+	l.Error("boom")
+
+	assert.Contains(t, console.String(), "ip=10.0.0.1")
+	assert.Contains(t, console.String(), "email=an***h@gmail.com")
+	assert.NotContains(t, file.String(), "10.0.0.1")
+	assert.Contains(t, file.String(), `"email":"an***h@gmail.com"`)
+	assert.NotContains(t, audit.String(), "login")
+	assert.Contains(t, audit.String(), "boom")
+	assert.True(t, json.Valid(bytes.Split(file.Bytes(), []byte("\n"))[0]))
+
+	// Sinks are hot-swappable like the rest of the config.
+	console.Reset()
+	l.UpdateConfig(
+		func(c *Config) {
+			c.Sink("console").Level = slog.LevelWarn
+		},
+	)
+	l.Info("quiet")
+	assert.Empty(t, console.String())
+	assert.Contains(t, file.String(), "quiet")
+}
+
+func TestLogger_Outputs(t *testing.T) {
+	shared := &bytes.Buffer{}
+	l := New(
+		WithSink(Sink{Name: "a", Output: writerFunc(shared.Write)}),
+		WithSink(Sink{Name: "b", Output: writerFunc(shared.Write)}),
+		WithSink(Sink{Name: "c", Output: shared}),
+		WithSink(Sink{Name: "d", Output: shared}),
+		WithSink(Sink{Name: "e", Output: wrappedWriter{writerFunc(shared.Write)}}),
+		WithSink(Sink{Name: "f", Output: wrappedWriter{writerFunc(shared.Write)}}),
+	)
+
+	var outputs []io.Writer
+	assert.NotPanics(t, func() { outputs = l.outputs() }, "writers that cannot be compared")
+	assert.Len(t, outputs, 5, "only comparable writers are deduplicated")
+	ctx := context.Background()
+	assert.NotPanics(t, func() { assert.NoError(t, l.Flush(ctx)) })
+	assert.NotPanics(t, func() { assert.NoError(t, l.SwapOutput(ctx, wrappedWriter{writerFunc(shared.Write)})) })
+	assert.NotPanics(t, func() { assert.NoError(t, l.Close()) })
+}
@@ -0,0 +1,244 @@
+package slogx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// SuppressedKey is the attribute with the number of records dropped by sampling
+	// or rate limiting in a suppression summary (see Config.SuppressionSummary).
+	SuppressedKey = "suppressed"
+	// SuppressedMsgKey is the attribute with the message of the dropped records.
+	SuppressedMsgKey = "suppressed_msg"
+)
+
+// maxThrottleKeys bounds the number of sampling and rate limit entries kept in memory.
+// When it is reached, idle entries are dropped, and all entries if none is idle.
+const maxThrottleKeys = 4096
+
+// Sampling thins out repeated records: within each Interval, the first First records
+// with the same message and level are logged, then every Thereafter-th one
+// (none if Thereafter is zero). The zero value disables sampling.
+type Sampling struct {
+	Interval   time.Duration
+	First      int
+	Thereafter int
+}
+
+func (s Sampling) enabled() bool {
+	return s.Interval > 0
+}
+
+// RateLimit is a token bucket per key: Rate records per second are allowed, with
+// bursts of up to Burst records (at least 1). The zero value disables rate limiting.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+	// Key selects the bucket of a record; nil uses the message and level.
+	Key func(r slog.Record) string
+}
+
+func (rl RateLimit) enabled() bool {
+	return rl.Rate > 0
+}
+
+// sampleKey identifies records that are "similar" for sampling and summaries.
+type sampleKey struct {
+	level slog.Level
+	msg   string
+}
+
+// sampleEntry counts the records of a sampleKey in the current sampling window.
+type sampleEntry struct {
+	start time.Time
+	count int
+}
+
+// suppressionKey identifies the records counted together in a suppression summary:
+// the rate limit bucket when RateLimit.Key is set, the message and level otherwise.
+type suppressionKey struct {
+	custom bool
+	key    string
+}
+
+// suppression counts the records of a suppressionKey dropped since the last summary.
+// The summary is written like the latest of them: at its level, through the handler
+// it was logged with (keeping the logger name and With attributes) and with its
+// context, without its cancellation.
+type suppression struct {
+	count int
+	level slog.Level
+	msg   string
+	h     *DynamicHandler
+	ctx   context.Context
+}
+
+// bucket is the token bucket of a rate limit key.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// throttle holds the sampling and rate limiting state of a logger. It outlives
+// configuration changes, which only change the limits applied to it.
+type throttle struct {
+	mu         sync.Mutex
+	samples    map[sampleKey]*sampleEntry
+	buckets    map[string]*bucket
+	suppressed map[suppressionKey]*suppression
+	timer      *time.Timer
+}
+
+// allow reports whether r, logged through h with ctx, passes the sampling and rate
+// limits of cfg. Dropped records are counted for the suppression summary.
+func (t *throttle) allow(ctx context.Context, h *DynamicHandler, cfg *Config, r slog.Record) bool {
+	if t == nil || (!cfg.Sampling.enabled() && !cfg.RateLimit.enabled()) {
+		return true
+	}
+
+	now := r.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	key := sampleKey{level: r.Level, msg: r.Message}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	summaryKey := suppressionKey{key: key.level.String() + " " + key.msg}
+	ok := t.sample(cfg.Sampling, key, now)
+	if ok && cfg.RateLimit.enabled() {
+		bucketKey := summaryKey.key
+		if cfg.RateLimit.Key != nil {
+			bucketKey = cfg.RateLimit.Key(r)
+		}
+		if ok = t.take(cfg.RateLimit, bucketKey, now); !ok && cfg.RateLimit.Key != nil {
+			summaryKey = suppressionKey{custom: true, key: bucketKey}
+		}
+	}
+
+	if !ok && cfg.SuppressionSummary > 0 {
+		t.suppress(ctx, summaryKey, h, r)
+		if t.timer == nil {
+			t.timer = time.AfterFunc(cfg.SuppressionSummary, t.summarize)
+		}
+	}
+	return ok
+}
+
+// suppress counts r, logged through h with ctx, for the summary of key. t.mu must be held.
+func (t *throttle) suppress(ctx context.Context, key suppressionKey, h *DynamicHandler, r slog.Record) {
+	if t.suppressed == nil {
+		t.suppressed = make(map[suppressionKey]*suppression)
+	}
+	s, ok := t.suppressed[key]
+	if !ok {
+		// Summaries are best effort: past the limit, new keys are not counted
+		if len(t.suppressed) >= maxThrottleKeys {
+			return
+		}
+		s = &suppression{}
+		t.suppressed[key] = s
+	}
+	s.count++
+	s.level, s.msg, s.h, s.ctx = r.Level, r.Message, h, context.WithoutCancel(ctx)
+}
+
+// sample applies s to the record counter of key. t.mu must be held.
+func (t *throttle) sample(s Sampling, key sampleKey, now time.Time) bool {
+	if !s.enabled() {
+		return true
+	}
+	if t.samples == nil {
+		t.samples = make(map[sampleKey]*sampleEntry)
+	}
+
+	e, ok := t.samples[key]
+	if !ok {
+		if len(t.samples) >= maxThrottleKeys {
+			for k, old := range t.samples {
+				if now.Sub(old.start) >= s.Interval {
+					delete(t.samples, k)
+				}
+			}
+			if len(t.samples) >= maxThrottleKeys {
+				clear(t.samples)
+			}
+		}
+		e = &sampleEntry{start: now}
+		t.samples[key] = e
+	}
+	if now.Sub(e.start) >= s.Interval {
+		e.start, e.count = now, 0
+	}
+
+	e.count++
+	if e.count <= s.First {
+		return true
+	}
+	return s.Thereafter > 0 && (e.count-s.First)%s.Thereafter == 0
+}
+
+// take takes a token from the bucket of key. t.mu must be held.
+func (t *throttle) take(rl RateLimit, key string, now time.Time) bool {
+	burst := float64(max(rl.Burst, 1))
+	if t.buckets == nil {
+		t.buckets = make(map[string]*bucket)
+	}
+
+	b, ok := t.buckets[key]
+	if !ok {
+		if len(t.buckets) >= maxThrottleKeys {
+			// A bucket that has refilled is indistinguishable from a new one
+			for k, old := range t.buckets {
+				if old.tokens+now.Sub(old.last).Seconds()*rl.Rate >= burst {
+					delete(t.buckets, k)
+				}
+			}
+			if len(t.buckets) >= maxThrottleKeys {
+				clear(t.buckets)
+			}
+		}
+		b = &bucket{tokens: burst, last: now}
+		t.buckets[key] = b
+	}
+
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed.Seconds()*rl.Rate)
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// summarize writes one record per message and level (or rate limit key, see
+// RateLimit.Key) that had records suppressed since the previous summary.
+func (t *throttle) summarize() {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	counts := t.suppressed
+	t.suppressed = nil
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+	t.mu.Unlock()
+
+	now := time.Now()
+	for _, s := range counts {
+		r := slog.NewRecord(now, s.level, fmt.Sprintf("%d similar records suppressed", s.count), 0)
+		r.AddAttrs(slog.Int(SuppressedKey, s.count), slog.String(SuppressedMsgKey, s.msg))
+		_ = s.h.handle(s.ctx, r)
+	}
+}
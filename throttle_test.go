@@ -0,0 +1,205 @@
+package slogx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logAt sends a record with an explicit time straight to the handler of l.
+func logAt(t *testing.T, l *Logger, ts time.Time, level slog.Level, msg string, args ...any) {
+	t.Helper()
+	r := slog.NewRecord(ts, level, msg, 0)
+	r.Add(args...)
+	require.NoError(t, l.Handler().Handle(context.Background(), r))
+}
+
+// jsonLines decodes every JSON record written to buf.
+func jsonLines(t *testing.T, buf string) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &m))
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func TestThrottle_Sampling(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(
+		WithOutput(&buf), WithFormat(FormatJSON),
+		WithSampling(Sampling{Interval: time.Second, First: 2, Thereafter: 3}),
+	)
+	t0 := time.Now()
+
+	for i := 1; i <= 10; i++ {
+		logAt(t, l, t0, slog.LevelInfo, "hot", "i", i)
+	}
+	logAt(t, l, t0, slog.LevelWarn, "hot", "i", 0) // another level is sampled separately
+
+	var kept []float64
+	for _, m := range jsonLines(t, buf.String()) {
+		if m["level"] == "INFO" {
+			kept = append(kept, m["i"].(float64))
+		}
+	}
+	assert.Equal(t, []float64{1, 2, 5, 8}, kept)
+	assert.Contains(t, buf.String(), `"level":"WARN"`)
+
+	// A new interval starts over
+	buf.Reset()
+	logAt(t, l, t0.Add(time.Second), slog.LevelInfo, "hot", "i", 11)
+	assert.Contains(t, buf.String(), `"i":11`)
+
+	// Sampling can be turned off at runtime
+	l.UpdateConfig(func(c *Config) { c.Sampling = Sampling{} })
+	buf.Reset()
+	for i := 0; i < 5; i++ {
+		logAt(t, l, t0.Add(time.Second), slog.LevelInfo, "hot")
+	}
+	assert.Len(t, jsonLines(t, buf.String()), 5)
+}
+
+func TestThrottle_RateLimit(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithFormat(FormatJSON), WithRateLimit(RateLimit{Rate: 1, Burst: 2}))
+	t0 := time.Now()
+
+	for i := 0; i < 5; i++ {
+		logAt(t, l, t0, slog.LevelInfo, "req")
+	}
+	assert.Len(t, jsonLines(t, buf.String()), 2, "burst")
+
+	logAt(t, l, t0.Add(500*time.Millisecond), slog.LevelInfo, "req")
+	assert.Len(t, jsonLines(t, buf.String()), 2, "half a token")
+	logAt(t, l, t0.Add(time.Second), slog.LevelInfo, "req")
+	assert.Len(t, jsonLines(t, buf.String()), 3, "refilled")
+
+	// Custom keys: one bucket per user, whatever the message
+	l.UpdateConfig(
+		func(c *Config) {
+			c.RateLimit = RateLimit{
+				Rate: 1, Burst: 1, Key: func(r slog.Record) string {
+					user := ""
+					r.Attrs(
+						func(a slog.Attr) bool {
+							if a.Key == "user" {
+								user = a.Value.String()
+							}
+							return true
+						},
+					)
+					return user
+				},
+			}
+		},
+	)
+	buf.Reset()
+	t1 := t0.Add(time.Hour)
+	logAt(t, l, t1, slog.LevelInfo, "a", "user", "alice")
+	logAt(t, l, t1, slog.LevelInfo, "b", "user", "alice")
+	logAt(t, l, t1, slog.LevelInfo, "a", "user", "bob")
+	assert.Len(t, jsonLines(t, buf.String()), 2)
+	assert.NotContains(t, buf.String(), `"msg":"b"`)
+}
+
+func TestThrottle_SuppressionSummary(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(
+		WithOutput(&buf), WithFormat(FormatJSON),
+		WithSampling(Sampling{Interval: time.Minute, First: 1}),
+		WithSuppressionSummary(time.Hour),
+	)
+	t0 := time.Now()
+	for i := 0; i < 4; i++ {
+		logAt(t, l, t0, slog.LevelWarn, "disk almost full")
+	}
+	require.Len(t, jsonLines(t, buf.String()), 1)
+
+	require.NoError(t, l.Flush(context.Background()))
+	lines := jsonLines(t, buf.String())
+	require.Len(t, lines, 2)
+	assert.Equal(t, "3 similar records suppressed", lines[1]["msg"])
+	assert.Equal(t, "WARN", lines[1]["level"])
+	assert.Equal(t, float64(3), lines[1][SuppressedKey])
+	assert.Equal(t, "disk almost full", lines[1][SuppressedMsgKey])
+
+	// Nothing pending: no further summary
+	require.NoError(t, l.Flush(context.Background()))
+	assert.Len(t, jsonLines(t, buf.String()), 2)
+}
+
+func TestThrottle_SuppressionSummaryTimer(t *testing.T) {
+	out := &syncBuffer{}
+	l := New(
+		WithOutput(out),
+		WithRateLimit(RateLimit{Rate: 0.001, Burst: 1}),
+		WithSuppressionSummary(20*time.Millisecond),
+	)
+	for i := 0; i < 3; i++ {
+		l.Info("flood")
+	}
+
+	assert.Eventually(
+		t, func() bool {
+			return strings.Contains(out.String(), `msg="2 similar records suppressed"`)
+		}, time.Second, 5*time.Millisecond,
+	)
+}
+
+func TestThrottle_SuppressionSummaryLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(
+		WithOutput(&buf), WithFormat(FormatJSON),
+		WithRateLimit(
+			RateLimit{
+				Rate: 0.001, Burst: 1, Key: func(r slog.Record) string {
+					user := ""
+					r.Attrs(
+						func(a slog.Attr) bool {
+							if a.Key == "user" {
+								user = a.Value.String()
+							}
+							return true
+						},
+					)
+					return user
+				},
+			},
+		),
+		WithSuppressionSummary(time.Hour),
+	)
+	api := l.Named("api").With("region", "eu")
+	t0 := time.Now()
+	for _, msg := range []string{"a", "b", "c"} {
+		logAt(t, api, t0, slog.LevelInfo, msg, "user", "alice")
+	}
+	logAt(t, api, t0, slog.LevelInfo, "a", "user", "bob")
+	logAt(t, api, t0, slog.LevelWarn, "d", "user", "bob")
+	require.Len(t, jsonLines(t, buf.String()), 2)
+
+	buf.Reset()
+	require.NoError(t, l.Flush(context.Background()))
+	lines := jsonLines(t, buf.String())
+	require.Len(t, lines, 2, "one summary per rate limit key")
+	byMsg := map[any]map[string]any{}
+	for _, m := range lines {
+		assert.Equal(t, "api", m[LoggerKey])
+		assert.Equal(t, "eu", m["region"])
+		byMsg[m[SuppressedMsgKey]] = m
+	}
+	assert.Equal(t, float64(2), byMsg["c"][SuppressedKey], "the summary follows the last dropped record")
+	assert.Equal(t, "WARN", byMsg["d"]["level"])
+}
@@ -0,0 +1,110 @@
+package slogx
+
+import (
+	"context"
+	"encoding/hex"
+	"log/slog"
+)
+
+// Attribute keys added for the active span (see Config.TraceExtractor).
+const (
+	TraceIDKey    = "trace_id"
+	SpanIDKey     = "span_id"
+	TraceFlagsKey = "trace_flags"
+)
+
+// TraceID is a W3C Trace Context trace identifier. It has the same layout as
+// trace.TraceID from OpenTelemetry, so the two convert directly.
+type TraceID [16]byte
+
+// IsValid reports whether the trace ID is not all zeros.
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+// String returns the trace ID as 32 lowercase hex characters.
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// SpanID is a W3C Trace Context span identifier. It has the same layout as
+// trace.SpanID from OpenTelemetry, so the two convert directly.
+type SpanID [8]byte
+
+// IsValid reports whether the span ID is not all zeros.
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+// String returns the span ID as 16 lowercase hex characters.
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// TraceFlags are the W3C Trace Context flags of a span.
+type TraceFlags byte
+
+// FlagsSampled is set when the span is sampled.
+const FlagsSampled TraceFlags = 0x01
+
+// IsSampled reports whether the sampled flag is set.
+func (f TraceFlags) IsSampled() bool {
+	return f&FlagsSampled != 0
+}
+
+// String returns the flags as 2 lowercase hex characters, as in a traceparent header.
+func (f TraceFlags) String() string {
+	return hex.EncodeToString([]byte{byte(f)})
+}
+
+// SpanContext identifies the span a record was logged in.
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	TraceFlags TraceFlags
+}
+
+// IsValid reports whether both the trace ID and the span ID are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// TraceExtractor finds the active span in a context. It is the bridge to a tracing
+// library, so slogx does not depend on one. For OpenTelemetry:
+//
+//	slogx.TraceExtractorFunc(func(ctx context.Context) (slogx.SpanContext, bool) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		return slogx.SpanContext{
+//			TraceID:    slogx.TraceID(sc.TraceID()),
+//			SpanID:     slogx.SpanID(sc.SpanID()),
+//			TraceFlags: slogx.TraceFlags(sc.TraceFlags()),
+//		}, sc.IsValid()
+//	})
+type TraceExtractor interface {
+	SpanContext(ctx context.Context) (SpanContext, bool)
+}
+
+// TraceExtractorFunc adapts a function to the TraceExtractor interface.
+type TraceExtractorFunc func(ctx context.Context) (SpanContext, bool)
+
+// SpanContext calls f(ctx).
+func (f TraceExtractorFunc) SpanContext(ctx context.Context) (SpanContext, bool) {
+	return f(ctx)
+}
+
+// traceAttrs returns trace_id, span_id and trace_flags for the span found in ctx,
+// or nil when there is no extractor or no valid span.
+func traceAttrs(ctx context.Context, te TraceExtractor) []slog.Attr {
+	if te == nil || ctx == nil {
+		return nil
+	}
+	sc, ok := te.SpanContext(ctx)
+	if !ok || !sc.IsValid() {
+		return nil
+	}
+	return []slog.Attr{
+		slog.String(TraceIDKey, sc.TraceID.String()),
+		slog.String(SpanIDKey, sc.SpanID.String()),
+		slog.String(TraceFlagsKey, sc.TraceFlags.String()),
+	}
+}
@@ -0,0 +1,92 @@
+package slogx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSpan stands in for a tracing library span stored in the context.
+type fakeSpan struct {
+	sc SpanContext
+}
+
+type fakeSpanKey struct{}
+
+func withFakeSpan(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, fakeSpanKey{}, &fakeSpan{sc: sc})
+}
+
+var fakeExtractor = TraceExtractorFunc(
+	func(ctx context.Context) (SpanContext, bool) {
+		span, ok := ctx.Value(fakeSpanKey{}).(*fakeSpan)
+		if !ok {
+			return SpanContext{}, false
+		}
+		return span.sc, true
+	},
+)
+
+func TestTraceCorrelation(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(WithOutput(buf), WithFormat(FormatJSON), WithTraceExtractor(fakeExtractor))
+
+	sc := SpanContext{
+		TraceID:    TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: FlagsSampled,
+	}
+	ctx := withFakeSpan(context.Background(), sc)
+
+	l.InfoContext(ctx, "handled", "status", 200)
+
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", m["trace_id"])
+	assert.Equal(t, "00f067aa0ba902b7", m["span_id"])
+	assert.Equal(t, "01", m["trace_flags"])
+	assert.Equal(t, float64(200), m["status"])
+
+	t.Run(
+		"no span", func(t *testing.T) {
+			buf.Reset()
+			l.InfoContext(context.Background(), "plain")
+			assert.NotContains(t, buf.String(), "trace_id")
+		},
+	)
+
+	t.Run(
+		"invalid span", func(t *testing.T) {
+			buf.Reset()
+			l.InfoContext(withFakeSpan(context.Background(), SpanContext{SpanID: sc.SpanID}), "plain")
+			assert.NotContains(t, buf.String(), "trace_id")
+		},
+	)
+
+	t.Run(
+		"extractor set at runtime", func(t *testing.T) {
+			buf.Reset()
+			l2 := New(WithOutput(buf), WithFormat(FormatJSON))
+			l2.InfoContext(ctx, "before")
+			assert.NotContains(t, buf.String(), "trace_id")
+
+			l2.UpdateConfig(func(c *Config) { c.TraceExtractor = fakeExtractor })
+			buf.Reset()
+			l2.InfoContext(ctx, "after")
+			assert.Contains(t, buf.String(), `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`)
+		},
+	)
+}
+
+func TestSpanContext(t *testing.T) {
+	assert.False(t, SpanContext{}.IsValid())
+	assert.False(t, SpanContext{TraceID: TraceID{1}}.IsValid())
+	assert.True(t, SpanContext{TraceID: TraceID{1}, SpanID: SpanID{1}}.IsValid())
+	assert.True(t, TraceFlags(0x03).IsSampled())
+	assert.False(t, TraceFlags(0).IsSampled())
+	assert.Equal(t, "00", TraceFlags(0).String())
+}
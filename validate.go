@@ -0,0 +1,208 @@
+package slogx
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidConfig is returned (wrapped) by Config.Validate and by the updates that
+// refuse to publish an invalid configuration.
+var ErrInvalidConfig = errors.New("slogx: invalid config")
+
+// Validate reports the problems that would make the configuration fail or behave
+// ambiguously at runtime:
+//   - a nil Output (unless Sinks are used) or Masker
+//   - a Format, or a sink format, that is not registered
+//   - a key both masked and removed, globally or within a sink
+//   - two levels with the same name, including the names of the standard slog
+//     levels (names are matched case-insensitively)
+//   - nil ContextExtractors or ScanPatterns without a pattern
+//   - negative sampling, rate limit, dedup or flight recorder settings
+//
+// The returned error wraps ErrInvalidConfig and lists every problem found.
+// Settings that are valid but probably unintended are reported by Warnings.
+func (c *Config) Validate() error {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if len(c.Sinks) == 0 {
+		if c.Output == nil {
+			add("Output is nil")
+		}
+		if !c.Format.registered() {
+			add("unknown Format %s", c.Format)
+		}
+	}
+	if c.Masker == nil {
+		add("Masker is nil")
+	}
+	for _, key := range conflictingKeys(c.MaskKeys, c.RemoveKeys) {
+		add("key %q is both in MaskKeys and RemoveKeys", key)
+	}
+
+	for i, s := range c.Sinks {
+		name := sinkName(i, s)
+		if s.Output != nil && !s.Format.registered() {
+			add("sink %s: unknown Format %s", name, s.Format)
+		}
+		maskKeys := mergeMaskKeys(c.MaskKeys, s.MaskKeys)
+		removeKeys := mergeRemoveKeys(c.RemoveKeys, s.RemoveKeys)
+		for _, key := range conflictingKeys(maskKeys, removeKeys) {
+			// Global conflicts are already reported above
+			if _, masked := c.MaskKeys[key]; masked {
+				if _, removed := c.RemoveKeys[key]; removed {
+					continue
+				}
+			}
+			add("sink %s: key %q is both masked and removed", name, key)
+		}
+	}
+
+	levels := make([]slog.Level, 0, len(c.LevelNames))
+	for l := range c.LevelNames {
+		levels = append(levels, l)
+	}
+	sort.Slice(
+		levels, func(i, j int) bool {
+			return levels[i] < levels[j]
+		},
+	)
+	// Standard levels keep their slog names unless renamed
+	seen := make(map[string]slog.Level, len(levels)+4)
+	for _, l := range []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError} {
+		if _, renamed := c.LevelNames[l]; !renamed {
+			seen[l.String()] = l
+		}
+	}
+	for _, l := range levels {
+		name := c.LevelNames[l]
+		if strings.TrimSpace(name) == "" {
+			add("level %s has an empty name", l)
+			continue
+		}
+		norm := strings.ToUpper(strings.TrimSpace(name))
+		if prev, ok := seen[norm]; ok {
+			add("level name %q is used by both %s and %s", name, prev, l)
+			continue
+		}
+		seen[norm] = l
+	}
+
+	for i, extract := range c.ContextExtractors {
+		if extract == nil {
+			add("ContextExtractors[%d] is nil", i)
+		}
+	}
+	for i, p := range c.ScanPatterns {
+		if p.Pattern == nil {
+			add("ScanPatterns[%d] (%s) has no Pattern", i, p.Name)
+		}
+	}
+
+	if c.Sampling.Interval < 0 || c.Sampling.First < 0 || c.Sampling.Thereafter < 0 {
+		add("Sampling has negative values")
+	} else if !c.Sampling.enabled() && (c.Sampling.First > 0 || c.Sampling.Thereafter > 0) {
+		add("Sampling has no Interval")
+	}
+	if c.RateLimit.Rate < 0 || math.IsNaN(c.RateLimit.Rate) || c.RateLimit.Burst < 0 {
+		add("RateLimit has an invalid Rate or Burst")
+	}
+	if c.SuppressionSummary < 0 {
+		add("SuppressionSummary is negative")
+	}
+	if c.Dedup.Window < 0 || c.Dedup.MaxWindow < 0 {
+		add("Dedup has a negative window")
+	} else if !c.Dedup.enabled() && len(c.Dedup.Keys) > 0 {
+		add("Dedup has no Window")
+	}
+
+	if c.FlightRecorder.Size < 0 {
+		add("FlightRecorder.Size is negative")
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidConfig, strings.Join(problems, "; "))
+}
+
+// Warnings reports settings that are valid but probably not what was meant: a mask
+// rule and a different remove rule that match the same attribute, globally or within
+// a sink, through group paths and wildcards ("email" masked and "user.email" or
+// "*.email" removed). Removal wins: such attributes are dropped, not masked. The same
+// key in both rule sets is an error (see Validate).
+//
+// Warnings are written by AdminHandler to the audit log and by WatchConfig with the
+// reload record.
+func (c *Config) Warnings() []string {
+	var warnings []string
+	for _, o := range overlappingRules(c.MaskKeys, c.RemoveKeys) {
+		warnings = append(warnings, fmt.Sprintf("mask rule %q overlaps remove rule %q: the key is removed", o[0], o[1]))
+	}
+
+	for i, s := range c.Sinks {
+		maskKeys := mergeMaskKeys(c.MaskKeys, s.MaskKeys)
+		removeKeys := mergeRemoveKeys(c.RemoveKeys, s.RemoveKeys)
+		for _, o := range overlappingRules(maskKeys, removeKeys) {
+			// Global overlaps are already reported above
+			if _, masked := c.MaskKeys[o[0]]; masked {
+				if _, removed := c.RemoveKeys[o[1]]; removed {
+					continue
+				}
+			}
+			warnings = append(
+				warnings,
+				fmt.Sprintf("sink %s: mask rule %q overlaps remove rule %q: the key is removed", sinkName(i, s), o[0], o[1]),
+			)
+		}
+	}
+	return warnings
+}
+
+// sinkName names a sink in problems and warnings, falling back to its index.
+func sinkName(i int, s Sink) string {
+	if s.Name == "" {
+		return fmt.Sprint(i)
+	}
+	return s.Name
+}
+
+// conflictingKeys returns the sorted keys present in both maskKeys and removeKeys.
+func conflictingKeys(maskKeys MaskMap, removeKeys RemoveMap) []string {
+	var keys []string
+	for key := range maskKeys {
+		if _, ok := removeKeys[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// overlappingRules returns the sorted pairs of different mask and remove rules that
+// match a common attribute path (see rulesOverlap).
+func overlappingRules(maskKeys MaskMap, removeKeys RemoveMap) [][2]string {
+	var pairs [][2]string
+	for mask := range maskKeys {
+		for remove := range removeKeys {
+			if mask != remove && rulesOverlap(mask, remove) {
+				pairs = append(pairs, [2]string{mask, remove})
+			}
+		}
+	}
+	sort.Slice(
+		pairs, func(i, j int) bool {
+			if pairs[i][0] != pairs[j][0] {
+				return pairs[i][0] < pairs[j][0]
+			}
+			return pairs[i][1] < pairs[j][1]
+		},
+	)
+	return pairs
+}
@@ -0,0 +1,188 @@
+package slogx
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	require.NoError(t, defaultOptions().initialConfig.Validate())
+
+	for name, tc := range map[string]struct {
+		fn   func(c *Config)
+		want string
+	}{
+		"nil output": {
+			func(c *Config) { c.Output = nil }, "Output is nil",
+		},
+		"nil masker": {
+			func(c *Config) { c.Masker = nil }, "Masker is nil",
+		},
+		"unknown format": {
+			func(c *Config) { c.Format = Format(99) }, "unknown Format Format(99)",
+		},
+		"mask and remove": {
+			func(c *Config) {
+				c.MaskKeys["password"] = MaskSecret
+				c.RemoveKeys["password"] = struct{}{}
+			},
+			`key "password" is both in MaskKeys and RemoveKeys`,
+		},
+		"duplicate level name": {
+			func(c *Config) { c.LevelNames[slog.Level(6)] = "trace" },
+			`level name "trace" is used by both DEBUG-4 and WARN+2`,
+		},
+		"standard level name": {
+			func(c *Config) { c.LevelNames[slog.Level(2)] = "info" },
+			`level name "info" is used by both INFO and INFO+2`,
+		},
+		"empty level name": {
+			func(c *Config) { c.LevelNames[slog.Level(2)] = " " }, "level INFO+2 has an empty name",
+		},
+		"nil extractor": {
+			func(c *Config) { c.ContextExtractors = append(c.ContextExtractors, nil) }, "ContextExtractors[0] is nil",
+		},
+		"nil pattern": {
+			func(c *Config) { c.ScanPatterns = append(c.ScanPatterns, ScanPattern{Name: "ssn"}) },
+			"ScanPatterns[0] (ssn) has no Pattern",
+		},
+		"negative sampling": {
+			func(c *Config) { c.Sampling = Sampling{Interval: time.Second, First: -1} }, "Sampling has negative values",
+		},
+		"sampling without interval": {
+			func(c *Config) { c.Sampling = Sampling{First: 10} }, "Sampling has no Interval",
+		},
+		"negative rate": {
+			func(c *Config) { c.RateLimit.Rate = -1 }, "RateLimit has an invalid Rate or Burst",
+		},
+		"dedup without window": {
+			func(c *Config) { c.Dedup.Keys = []string{"error"} }, "Dedup has no Window",
+		},
+		"sink format": {
+			func(c *Config) {
+				c.Sinks = []Sink{{Name: "audit", Output: &bytes.Buffer{}, Format: Format(-1)}}
+			},
+			"sink audit: unknown Format Format(-1)",
+		},
+		"sink mask and remove": {
+			func(c *Config) {
+				c.MaskKeys["email"] = MaskEmail
+				c.Sinks = []Sink{{Output: &bytes.Buffer{}, RemoveKeys: RemoveMap{"email": {}}}}
+			},
+			`sink 0: key "email" is both masked and removed`,
+		},
+	} {
+		t.Run(
+			name, func(t *testing.T) {
+				c := defaultOptions().initialConfig
+				tc.fn(c)
+				err := c.Validate()
+				require.ErrorIs(t, err, ErrInvalidConfig)
+				assert.Contains(t, err.Error(), tc.want)
+			},
+		)
+	}
+
+	// Sinks replace Output, and sinks without an output are skipped
+	c := defaultOptions().initialConfig
+	c.Output = nil
+	c.Sinks = []Sink{{Name: "off"}, {Name: "json", Output: &bytes.Buffer{}, Format: FormatJSON}}
+	c.ScanPatterns = []ScanPattern{{Name: "ssn", Pattern: regexp.MustCompile(`\d{3}-\d{2}-\d{4}`)}}
+	assert.NoError(t, c.Validate())
+
+	// Every problem is reported
+	c = defaultOptions().initialConfig
+	c.Output, c.Masker = nil, nil
+	err := c.Validate()
+	assert.Contains(t, err.Error(), "Output is nil")
+	assert.Contains(t, err.Error(), "Masker is nil")
+}
+
+func TestConfig_Warnings(t *testing.T) {
+	c := defaultOptions().initialConfig
+	assert.Empty(t, c.Warnings())
+
+	c.MaskKeys = MaskMap{"email": MaskEmail, "user.phone": MaskPhone, "card": MaskCard}
+	c.RemoveKeys = RemoveMap{"user.email": {}, "*.phone": {}, "request.**.card": {}, "token": {}}
+	c.Sinks = []Sink{
+		{Name: "audit", Output: &bytes.Buffer{}, RemoveKeys: RemoveMap{"*.email": {}}},
+		{Output: &bytes.Buffer{}, MaskKeys: MaskMap{"request.token": MaskSecret}},
+	}
+	assert.Equal(
+		t, []string{
+			`mask rule "card" overlaps remove rule "request.**.card": the key is removed`,
+			`mask rule "email" overlaps remove rule "user.email": the key is removed`,
+			`mask rule "user.phone" overlaps remove rule "*.phone": the key is removed`,
+			`sink audit: mask rule "email" overlaps remove rule "*.email": the key is removed`,
+			`sink 1: mask rule "request.token" overlaps remove rule "token": the key is removed`,
+		}, c.Warnings(),
+	)
+	assert.NoError(t, c.Validate(), "overlaps are not errors")
+
+	for _, tc := range []struct {
+		a, b string
+		want bool
+	}{
+		{"email", "user.email", true},
+		{"email", "*.email", true},
+		{"user.email", "*.email", true},
+		{"user.email", "admin.email", false},
+		{"*.email", "email", true},
+		{"user.*", "*.email", true},
+		{"a.**.b", "a.b", true},
+		{"a.**.b", "a.x.y.b", true},
+		{"a.**.b", "c.b", false},
+		{"email", "phone", false},
+		{"*", "user.email", false},
+	} {
+		assert.Equal(t, tc.want, rulesOverlap(tc.a, tc.b), "%s and %s", tc.a, tc.b)
+		assert.Equal(t, tc.want, rulesOverlap(tc.b, tc.a), "%s and %s", tc.b, tc.a)
+	}
+}
+
+func TestLogger_NilOutputAndMasker(t *testing.T) {
+	l := New(WithMaskKey("email", MaskEmail))
+	l.UpdateConfig(func(c *Config) { c.Output, c.Masker = nil, nil })
+	assert.NotPanics(t, func() { l.Info("login", "email", "antonioh@gmail.com") })
+}
+
+func TestLogger_TryUpdateConfig(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf))
+	before := l.Config()
+
+	err := l.TryUpdateConfig(func(c *Config) { c.Output = nil })
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	assert.Same(t, before, l.Config(), "an invalid config must not be published")
+
+	err = l.UpdateConfigIf(before.Version, func(c *Config) { c.Masker = nil })
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	assert.Same(t, before, l.Config())
+
+	require.NoError(t, l.TryUpdateConfig(func(c *Config) { c.Format = FormatJSON }))
+	l.Info("still logging")
+	assert.Contains(t, buf.String(), `"msg":"still logging"`)
+
+	// While an override is active, the base is validated together with the overrides
+	o := l.UpdateConfigFor(func(c *Config) { c.MaskKeys["token"] = MaskSecret }, time.Hour)
+	defer o.Cancel()
+	cur := l.Config()
+	err = l.TryUpdateConfig(func(c *Config) { c.RemoveKeys["token"] = struct{}{} })
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	assert.Same(t, cur, l.Config())
+
+	_, err = l.startOverride(nil, true, func(c *Config) { c.Output = nil }, time.Hour)
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	assert.Same(t, cur, l.Config())
+
+	require.True(t, o.Cancel())
+	l.InfoContext(context.Background(), "after override")
+	assert.Contains(t, buf.String(), `"msg":"after override"`)
+}